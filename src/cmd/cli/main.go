@@ -2,15 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/TheJubadze/RateLimiter/proto/pb"
 	"github.com/spf13/cobra"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 var (
@@ -40,6 +48,10 @@ func executeGRPCCommand(ip string, grpcFunc func(client pb.RateLimiterClient, ct
 		fmt.Println("IP must be provided")
 		return
 	}
+	runGRPCCommand(time.Second, grpcFunc)
+}
+
+func runGRPCCommand(timeout time.Duration, grpcFunc func(client pb.RateLimiterClient, ctx context.Context) (string, error)) {
 	conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
@@ -49,26 +61,172 @@ func executeGRPCCommand(ip string, grpcFunc func(client pb.RateLimiterClient, ct
 	}(conn)
 
 	client := pb.NewRateLimiterClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	message, err := grpcFunc(client, ctx)
 	if err != nil {
-		log.Printf("command execution failed: %v", err)
+		log.Printf("command execution failed: %s", describeError(err))
 	}
 	fmt.Println(message)
 }
 
+// describeError returns the code and message of a status error, followed by
+// a line per detail telling what to fix.
+func describeError(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+	lines := []string{fmt.Sprintf("%s: %s", st.Code(), st.Message())}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				lines = append(lines, fmt.Sprintf("  %s: %s", violation.GetField(), violation.GetDescription()))
+			}
+		case *errdetails.PreconditionFailure:
+			for _, violation := range detail.GetViolations() {
+				lines = append(lines, "  "+violation.GetDescription())
+			}
+		case *errdetails.ResourceInfo:
+			lines = append(lines, fmt.Sprintf("  %s %s", detail.GetResourceType(), detail.GetResourceName()))
+		case *errdetails.ErrorInfo:
+			lines = append(lines, fmt.Sprintf("  %s: %s", detail.GetReason(), detail.GetMetadata()["backend"]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// optionalDuration returns d as a protobuf duration, nil if it is zero.
+func optionalDuration(d time.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(d)
+}
+
+// addMetadataFlags adds the flags describing why and by whom a network is listed.
+func addMetadataFlags(cmd *cobra.Command) {
+	cmd.Flags().String("reason", "", "Why the IP is listed")
+	cmd.Flags().String("created-by", os.Getenv("USER"), "Who lists the IP")
+	cmd.Flags().String("ticket", "", "Reference to the issue the IP is listed for")
+	cmd.Flags().StringToString("label", nil, "Label to attach, as key=value, may be repeated")
+}
+
+func metadataFlags(cmd *cobra.Command) (reason, createdBy, ticket string, labels map[string]string) {
+	reason, _ = cmd.Flags().GetString("reason")
+	createdBy, _ = cmd.Flags().GetString("created-by")
+	ticket, _ = cmd.Flags().GetString("ticket")
+	labels, _ = cmd.Flags().GetStringToString("label")
+	return reason, createdBy, ticket, labels
+}
+
+// addRuleFlags adds the flags setting what happens to the requests from a network.
+func addRuleFlags(cmd *cobra.Command, defaultAction string) {
+	cmd.Flags().String("action", "", "What happens to requests from the IP: allow, deny, limit (check every limit) or bypass_ip_limit, "+defaultAction+" if not set")
+	cmd.Flags().Int32("priority", 0, "Of the rules matching a request, the one of highest priority decides")
+}
+
+func ruleFlags(cmd *cobra.Command) (pb.RuleAction, int32, error) {
+	action, _ := cmd.Flags().GetString("action")
+	priority, _ := cmd.Flags().GetInt32("priority")
+	if action == "" {
+		return pb.RuleAction_RULE_ACTION_UNSPECIFIED, priority, nil
+	}
+	value, ok := pb.RuleAction_value["RULE_ACTION_"+strings.ToUpper(action)]
+	if !ok || value == int32(pb.RuleAction_RULE_ACTION_UNSPECIFIED) {
+		return 0, 0, fmt.Errorf("unknown action: %q", action)
+	}
+	return pb.RuleAction(value), priority, nil
+}
+
+// formatEntry formats a listed network on one line.
+func formatEntry(entry *pb.ListEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\t%s", listName(entry.List), entry.Network)
+	if entry.Action != pb.RuleAction_RULE_ACTION_UNSPECIFIED {
+		fmt.Fprintf(&b, "\taction=%s", strings.ToLower(strings.TrimPrefix(entry.Action.String(), "RULE_ACTION_")))
+	}
+	if entry.Priority != 0 {
+		fmt.Fprintf(&b, "\tpriority=%d", entry.Priority)
+	}
+	if entry.Reason != "" {
+		fmt.Fprintf(&b, "\treason=%q", entry.Reason)
+	}
+	if entry.CreatedBy != "" {
+		fmt.Fprintf(&b, "\tcreated_by=%s", entry.CreatedBy)
+	}
+	if entry.Ticket != "" {
+		fmt.Fprintf(&b, "\tticket=%s", entry.Ticket)
+	}
+	if entry.Source != "" {
+		fmt.Fprintf(&b, "\tsource=%s", entry.Source)
+	}
+	keys := make([]string, 0, len(entry.Labels))
+	for key := range entry.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\t%s=%s", key, entry.Labels[key])
+	}
+	if entry.CreatedAt != nil {
+		fmt.Fprintf(&b, "\tcreated_at=%s", entry.CreatedAt.AsTime().Format(time.RFC3339))
+	}
+	if entry.ExpiresAt != nil {
+		fmt.Fprintf(&b, "\texpires_at=%s", entry.ExpiresAt.AsTime().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// listName names a list the way the commands do.
+func listName(list pb.ListType) string {
+	return strings.ToLower(strings.TrimPrefix(list.String(), "LIST_TYPE_"))
+}
+
+// formatOverlap prints an overlap as the supernet, then the subnet it holds.
+func formatOverlap(overlap *pb.Overlap) string {
+	return fmt.Sprintf("%s %s holds %s %s",
+		listName(overlap.SupernetList), overlap.Supernet, listName(overlap.SubnetList), overlap.Subnet)
+}
+
+func withOverlaps(message string, overlaps []*pb.Overlap) string {
+	lines := []string{message}
+	for _, overlap := range overlaps {
+		lines = append(lines, formatOverlap(overlap))
+	}
+	return strings.Join(lines, "\n")
+}
+
 var addToWhitelistCmd = &cobra.Command{
 	Use:   "add-wl",
 	Short: "Add an IP to the whitelist",
 	Run: func(cmd *cobra.Command, _ []string) {
 		ip, _ := cmd.Flags().GetString("ip")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		force, _ := cmd.Flags().GetBool("force")
+		reason, createdBy, ticket, labels := metadataFlags(cmd)
+		action, priority, err := ruleFlags(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		executeGRPCCommand(ip, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
-			response, err := client.AddToWhitelist(ctx, &pb.AddToWhitelistRequest{Ip: ip})
+			response, err := client.AddToWhitelist(ctx, &pb.AddToWhitelistRequest{
+				Ip:        ip,
+				Ttl:       optionalDuration(ttl),
+				Reason:    reason,
+				CreatedBy: createdBy,
+				Ticket:    ticket,
+				Labels:    labels,
+				Force:     force,
+				Action:    action,
+				Priority:  priority,
+			})
 			if err != nil {
 				return "", err
 			}
-			return response.Message, nil
+			return withOverlaps(response.Message, response.Overlaps), nil
 		})
 	},
 }
@@ -78,12 +236,30 @@ var addToBlacklistCmd = &cobra.Command{
 	Short: "Add an IP to the blacklist",
 	Run: func(cmd *cobra.Command, _ []string) {
 		ip, _ := cmd.Flags().GetString("ip")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		force, _ := cmd.Flags().GetBool("force")
+		reason, createdBy, ticket, labels := metadataFlags(cmd)
+		action, priority, err := ruleFlags(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		executeGRPCCommand(ip, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
-			response, err := client.AddToBlacklist(ctx, &pb.AddToBlacklistRequest{Ip: ip})
+			response, err := client.AddToBlacklist(ctx, &pb.AddToBlacklistRequest{
+				Ip:        ip,
+				Ttl:       optionalDuration(ttl),
+				Reason:    reason,
+				CreatedBy: createdBy,
+				Ticket:    ticket,
+				Labels:    labels,
+				Force:     force,
+				Action:    action,
+				Priority:  priority,
+			})
 			if err != nil {
 				return "", err
 			}
-			return response.Message, nil
+			return withOverlaps(response.Message, response.Overlaps), nil
 		})
 	},
 }
@@ -118,16 +294,318 @@ var removeFromBlacklistCmd = &cobra.Command{
 	},
 }
 
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Show the listed networks an IP or network falls into",
+	Run: func(cmd *cobra.Command, _ []string) {
+		ip, _ := cmd.Flags().GetString("ip")
+		executeGRPCCommand(ip, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
+			response, err := client.InspectNetwork(ctx, &pb.InspectNetworkRequest{Ip: ip})
+			if err != nil {
+				return "", err
+			}
+			if len(response.Entries) == 0 {
+				return fmt.Sprintf("%s is not listed", ip), nil
+			}
+			lines := make([]string, len(response.Entries))
+			for i, entry := range response.Entries {
+				lines[i] = formatEntry(entry)
+			}
+			return strings.Join(lines, "\n"), nil
+		})
+	},
+}
+
+var checkListsCmd = &cobra.Command{
+	Use:   "check-lists",
+	Short: "Show the listed networks holding other listed networks",
+	Run: func(_ *cobra.Command, _ []string) {
+		runGRPCCommand(time.Second, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
+			response, err := client.CheckListConsistency(ctx, &pb.CheckListConsistencyRequest{})
+			if err != nil {
+				return "", err
+			}
+			if len(response.Overlaps) == 0 {
+				return "No listed networks overlap", nil
+			}
+			return withOverlaps(fmt.Sprintf("%d overlaps:", len(response.Overlaps)), response.Overlaps), nil
+		})
+	},
+}
+
+var listWhitelistCmd = &cobra.Command{
+	Use:   "ls-wl",
+	Short: "List the whitelisted networks",
+	Run: func(cmd *cobra.Command, _ []string) {
+		executeListCommand(cmd, func(client pb.RateLimiterClient, ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+			return client.ListWhitelist(ctx, req)
+		})
+	},
+}
+
+var listBlacklistCmd = &cobra.Command{
+	Use:   "ls-bl",
+	Short: "List the blacklisted networks",
+	Run: func(cmd *cobra.Command, _ []string) {
+		executeListCommand(cmd, func(client pb.RateLimiterClient, ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+			return client.ListBlacklist(ctx, req)
+		})
+	},
+}
+
+// addListFlags adds the flags selecting a page of a list.
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().Int32("page-size", 0, "How many networks to list, 100 if not set, at most 1000")
+	cmd.Flags().String("page-token", "", "Token printed with the previous page")
+	cmd.Flags().String("contains", "", "List only the networks holding this IP or network")
+	cmd.Flags().Bool("newest-first", false, "List the newest networks first")
+}
+
+func executeListCommand(cmd *cobra.Command, list func(client pb.RateLimiterClient, ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error)) {
+	req := &pb.ListRequest{}
+	req.PageSize, _ = cmd.Flags().GetInt32("page-size")
+	req.PageToken, _ = cmd.Flags().GetString("page-token")
+	req.Contains, _ = cmd.Flags().GetString("contains")
+	if newestFirst, _ := cmd.Flags().GetBool("newest-first"); newestFirst {
+		req.Order = pb.SortOrder_SORT_ORDER_NEWEST_FIRST
+	}
+
+	runGRPCCommand(time.Second, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
+		response, err := list(client, ctx, req)
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, 0, len(response.Entries)+1)
+		for _, entry := range response.Entries {
+			lines = append(lines, formatEntry(entry))
+		}
+		if response.NextPageToken != "" {
+			lines = append(lines, "Next page: --page-token="+response.NextPageToken)
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+}
+
+// importChunkSize is how much of a file an import sends per message.
+const importChunkSize = 64 * 1024
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a list from a file",
+	Run: func(cmd *cobra.Command, _ []string) {
+		list, format, err := listFlags(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		path, _ := cmd.Flags().GetString("file")
+		replace, _ := cmd.Flags().GetBool("replace")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		mode := pb.ImportMode_IMPORT_MODE_MERGE
+		if replace {
+			mode = pb.ImportMode_IMPORT_MODE_REPLACE
+		}
+		runGRPCCommand(timeout, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
+			stream, err := client.ImportList(ctx)
+			if err != nil {
+				return "", err
+			}
+			req := &pb.ImportListRequest{List: list, Format: format, Mode: mode}
+			buf := make([]byte, importChunkSize)
+			for {
+				n, err := file.Read(buf)
+				if n > 0 {
+					req.Data = buf[:n]
+					if err := stream.Send(req); err != nil {
+						return "", err
+					}
+					req = &pb.ImportListRequest{}
+				}
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return "", err
+				}
+			}
+			// An empty file still says which list to import into
+			if req.List != pb.ListType_LIST_TYPE_UNSPECIFIED {
+				if err := stream.Send(req); err != nil {
+					return "", err
+				}
+			}
+
+			response, err := stream.CloseAndRecv()
+			if err != nil {
+				return "", err
+			}
+			if len(response.Errors) > 0 {
+				lines := make([]string, 0, len(response.Errors)+1)
+				lines = append(lines, fmt.Sprintf("Nothing imported, %d invalid lines:", len(response.Errors)))
+				for _, lineErr := range response.Errors {
+					lines = append(lines, fmt.Sprintf("line %d: %s", lineErr.Line, lineErr.Message))
+				}
+				return strings.Join(lines, "\n"), nil
+			}
+			return fmt.Sprintf("Imported %d networks, removed %d", response.Imported, response.Removed), nil
+		})
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a list to a file",
+	Run: func(cmd *cobra.Command, _ []string) {
+		list, format, err := listFlags(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		path, _ := cmd.Flags().GetString("file")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		out := os.Stdout
+		if path != "-" {
+			if out, err = os.Create(path); err != nil {
+				fmt.Println(err)
+				return
+			}
+			defer func() {
+				_ = out.Close()
+			}()
+		}
+
+		runGRPCCommand(timeout, func(client pb.RateLimiterClient, ctx context.Context) (string, error) {
+			stream, err := client.ExportList(ctx, &pb.ExportListRequest{List: list, Format: format})
+			if err != nil {
+				return "", err
+			}
+			for {
+				response, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return "", err
+				}
+				if _, err := out.Write(response.Data); err != nil {
+					return "", err
+				}
+			}
+			if path == "-" {
+				return "", nil
+			}
+			return fmt.Sprintf("Exported to %s", path), nil
+		})
+	},
+}
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check the health of the server, exiting with 1 unless it is serving",
+	Run: func(cmd *cobra.Command, _ []string) {
+		service, _ := cmd.Flags().GetString("service")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("did not connect: %v", err)
+		}
+		defer func(conn *grpc.ClientConn) {
+			_ = conn.Close()
+		}(conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			fmt.Printf("health check failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(response.Status)
+		if response.Status != healthpb.HealthCheckResponse_SERVING {
+			os.Exit(1)
+		}
+	},
+}
+
+// addTransferFlags adds the flags of import and export.
+func addTransferFlags(cmd *cobra.Command, fileUsage string) {
+	cmd.Flags().String("list", "", "List to transfer, whitelist or blacklist")
+	cmd.Flags().String("file", "", fileUsage)
+	cmd.Flags().String("format", "text", "File format: text (a network per line), csv or json (an object per line)")
+	cmd.Flags().Duration("timeout", time.Minute, "How long the transfer may take")
+}
+
+func listFlags(cmd *cobra.Command) (pb.ListType, pb.ListFormat, error) {
+	list, _ := cmd.Flags().GetString("list")
+	format, _ := cmd.Flags().GetString("format")
+	path, _ := cmd.Flags().GetString("file")
+	if path == "" {
+		return 0, 0, errors.New("file must be provided")
+	}
+
+	listType, ok := pb.ListType_value["LIST_TYPE_"+strings.ToUpper(list)]
+	if !ok || listType == 0 {
+		return 0, 0, fmt.Errorf("unknown list: %q", list)
+	}
+	listFormat, ok := pb.ListFormat_value["LIST_FORMAT_"+strings.ToUpper(format)]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown format: %q", format)
+	}
+	return pb.ListType(listType), pb.ListFormat(listFormat), nil
+}
+
 func init() {
 	rootCmd.AddCommand(addToWhitelistCmd)
 	addToWhitelistCmd.Flags().String("ip", "", "IP to add to the whitelist")
+	addToWhitelistCmd.Flags().Duration("ttl", 0, "How long the IP stays whitelisted, e.g. 30m, for good if not set")
+	addToWhitelistCmd.Flags().Bool("force", false, "Add the IP even if it overlaps listed networks")
+	addRuleFlags(addToWhitelistCmd, "allow")
+	addMetadataFlags(addToWhitelistCmd)
 
 	rootCmd.AddCommand(addToBlacklistCmd)
 	addToBlacklistCmd.Flags().String("ip", "", "IP to add to the blacklist")
+	addToBlacklistCmd.Flags().Duration("ttl", 0, "How long the IP stays blacklisted, e.g. 30m, for good if not set")
+	addToBlacklistCmd.Flags().Bool("force", false, "Add the IP even if it overlaps listed networks")
+	addRuleFlags(addToBlacklistCmd, "deny")
+	addMetadataFlags(addToBlacklistCmd)
 
 	rootCmd.AddCommand(removeFromWhitelistCmd)
 	removeFromWhitelistCmd.Flags().String("ip", "", "IP to remove from the whitelist")
 
 	rootCmd.AddCommand(removeFromBlacklistCmd)
 	removeFromBlacklistCmd.Flags().String("ip", "", "IP to remove from the blacklist")
+
+	rootCmd.AddCommand(inspectCmd)
+	inspectCmd.Flags().String("ip", "", "IP or network to inspect")
+
+	rootCmd.AddCommand(checkListsCmd)
+
+	rootCmd.AddCommand(listWhitelistCmd)
+	addListFlags(listWhitelistCmd)
+
+	rootCmd.AddCommand(listBlacklistCmd)
+	addListFlags(listBlacklistCmd)
+
+	rootCmd.AddCommand(importCmd)
+	addTransferFlags(importCmd, "File to import")
+	importCmd.Flags().Bool("replace", false, "Replace the list with the imported networks instead of adding them")
+
+	rootCmd.AddCommand(exportCmd)
+	addTransferFlags(exportCmd, "File to export to, - for the standard output")
+
+	rootCmd.AddCommand(healthCmd)
+	healthCmd.Flags().String("service", "", "Service to check, the whole server if not set")
+	healthCmd.Flags().Duration("timeout", 3*time.Second, "How long to wait for the server")
 }
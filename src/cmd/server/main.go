@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/TheJubadze/RateLimiter/internal/app"
 )
@@ -13,5 +18,12 @@ func init() {
 }
 
 func main() {
-	app.StartServer(configFile)
+	// Shut down gracefully on SIGTERM, e.g. during rolling deploys
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := app.StartServer(ctx, *configFile)
+	stop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,14 @@
+package systemclock
+
+import "time"
+
+// SystemClock is the wall clock.
+type SystemClock struct{}
+
+func New() SystemClock {
+	return SystemClock{}
+}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,282 @@
+package feeds
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/TheJubadze/RateLimiter/internal/listfile"
+)
+
+// Format is the format of a feed file.
+type Format string
+
+const (
+	// Text has a network or IP per line. Blank lines and anything after a
+	// # are ignored.
+	Text Format = "text"
+	// Netset is the FireHOL netset format, the same as Text.
+	Netset Format = "netset"
+	// Drop is the Spamhaus DROP format: a network per line followed by a
+	// semicolon and the SBL reference, which becomes the ticket. Lines
+	// starting with a semicolon are comments.
+	Drop Format = "drop"
+)
+
+// Feed is a file of networks kept in sync with one of the lists.
+type Feed struct {
+	// Name identifies the entries pulled from the feed
+	Name string
+	// Path or URL the feed is read from, only one of them is set
+	Path string
+	URL  string
+	// Format is the format of the file, Text if empty
+	Format Format
+	// RefreshInterval is how often the feed is read again, it is read only
+	// once if it is not positive
+	RefreshInterval time.Duration
+	// List is the list the networks go on, the whitelist or the blacklist
+	List string
+}
+
+// Validate checks that the feed can be synced.
+func (f Feed) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("feed name must be set")
+	}
+	if (f.Path == "") == (f.URL == "") {
+		return fmt.Errorf("feed %s: exactly one of path and url must be set", f.Name)
+	}
+	switch f.Format {
+	case "", Text, Netset, Drop:
+	default:
+		return fmt.Errorf("feed %s: unknown format: %q", f.Name, f.Format)
+	}
+	if f.List != "whitelist" && f.List != "blacklist" {
+		return fmt.Errorf("feed %s: unknown list: %q", f.Name, f.List)
+	}
+	return nil
+}
+
+// Syncer keeps the lists in sync with the feeds. Every refresh is diffed
+// against the entries pulled from the feed before, and only the changes are
+// applied, so entries added by hand or by other feeds are never touched.
+// Every replica syncs, applying changes another one already did is a no-op.
+type Syncer struct {
+	logger     logger.Logger
+	repository iplists.Repository
+	client     *http.Client
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewSyncer checks the feeds and starts syncing each of them, right away
+// and then at its refresh interval, until Close is called.
+func NewSyncer(logger logger.Logger, repository iplists.Repository, client *http.Client, feeds []Feed) (*Syncer, error) {
+	names := make(map[string]bool, len(feeds))
+	for _, feed := range feeds {
+		if err := feed.Validate(); err != nil {
+			return nil, err
+		}
+		if names[feed.Name] {
+			return nil, fmt.Errorf("duplicate feed name: %q", feed.Name)
+		}
+		names[feed.Name] = true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Syncer{
+		logger:     logger,
+		repository: repository,
+		client:     client,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	for _, feed := range feeds {
+		s.wg.Add(1)
+		go s.syncLoop(feed)
+	}
+
+	return s, nil
+}
+
+// Close stops syncing and waits for the syncs in progress to end.
+func (s *Syncer) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Syncer) syncLoop(feed Feed) {
+	defer s.wg.Done()
+
+	s.syncAndLog(feed)
+	if feed.RefreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(feed.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncAndLog(feed)
+		}
+	}
+}
+
+func (s *Syncer) syncAndLog(feed Feed) {
+	result, err := s.Sync(s.ctx, feed)
+	if err != nil {
+		s.logger.Printf("Failed to sync feed %s: %v", feed.Name, err)
+		return
+	}
+	if result.Imported > 0 || result.Removed > 0 {
+		s.logger.Printf("Synced feed %s: added %d networks to the %s, removed %d", feed.Name, result.Imported, feed.List, result.Removed)
+	}
+}
+
+// Sync reads the feed and applies what changed since the last sync.
+func (s *Syncer) Sync(ctx context.Context, feed Feed) (database.ImportResult, error) {
+	entries, err := s.fetch(ctx, feed)
+	if err != nil {
+		return database.ImportResult{}, err
+	}
+
+	current, err := s.repository.GetSourceNetworks(feed.List, feed.Name)
+	if err != nil {
+		return database.ImportResult{}, err
+	}
+	// An empty file is more likely a broken download than an empty feed
+	if len(entries) == 0 && len(current) > 0 {
+		return database.ImportResult{}, fmt.Errorf("feed is empty, keeping its %d networks", len(current))
+	}
+
+	listed := make(map[string]bool, len(current))
+	for _, entry := range current {
+		listed[entry.Network] = true
+	}
+	var add []database.Entry
+	for _, entry := range entries {
+		if listed[entry.Network] {
+			delete(listed, entry.Network)
+			continue
+		}
+		add = append(add, entry)
+	}
+	remove := make([]string, 0, len(listed))
+	for network := range listed {
+		remove = append(remove, network)
+	}
+
+	if len(add) == 0 && len(remove) == 0 {
+		return database.ImportResult{}, nil
+	}
+	return s.repository.SyncNetworks(feed.List, feed.Name, add, remove)
+}
+
+// fetch reads the networks of a feed, skipping the invalid lines and
+// networks listed more than once.
+func (s *Syncer) fetch(ctx context.Context, feed Feed) ([]database.Entry, error) {
+	r, err := s.open(ctx, feed)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var entries []database.Entry
+	seen := make(map[string]bool)
+	invalid := 0
+	add := func(entry database.Entry) {
+		if !seen[entry.Network] {
+			seen[entry.Network] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	if feed.Format == Drop {
+		err = readDrop(r, func(entry database.Entry, err error) {
+			if err != nil {
+				invalid++
+				return
+			}
+			add(entry)
+		})
+	} else {
+		err = listfile.Read(r, listfile.Text, func(_ int, entry database.Entry, err error) error {
+			if err != nil {
+				invalid++
+				return nil
+			}
+			add(entry)
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if invalid > 0 {
+		s.logger.Printf("Skipped %d invalid lines of feed %s", invalid, feed.Name)
+	}
+	return entries, nil
+}
+
+func (s *Syncer) open(ctx context.Context, feed Feed) (io.ReadCloser, error) {
+	if feed.Path != "" {
+		return os.Open(feed.Path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// readDrop reads a file in the Spamhaus DROP format.
+func readDrop(r io.Reader, fn func(entry database.Entry, err error)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, ticket, _ := strings.Cut(scanner.Text(), ";")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(line)
+		if err != nil {
+			fn(database.Entry{}, err)
+			continue
+		}
+		fn(database.Entry{
+			Network:  prefix.Masked().String(),
+			Metadata: database.Metadata{Ticket: strings.TrimSpace(ticket)},
+		}, nil)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,143 @@
+package feeds_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/feeds"
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const dropFile = `; Spamhaus DROP List 2024/01/01 - (c) 2024 The Spamhaus Project
+; Last-Modified: Mon, 01 Jan 2024 00:00:00 GMT
+1.10.16.0/20 ; SBL256894
+1.19.0.0/16 ; SBL434604
+not a network ; SBL1
+1.19.0.0/16 ; SBL434604
+`
+
+func newSyncer(t *testing.T) (*feeds.Syncer, *iplists.MockRepository) {
+	t.Helper()
+	repository := new(iplists.MockRepository)
+	syncer, err := feeds.NewSyncer(logruslogger.NewLogrusLogger("panic"), repository, http.DefaultClient, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = syncer.Close()
+	})
+	return syncer, repository
+}
+
+func serve(t *testing.T, body string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestSyncAppliesChanges(t *testing.T) {
+	syncer, repository := newSyncer(t)
+	feed := feeds.Feed{Name: "drop", URL: serve(t, dropFile), Format: feeds.Drop, List: "blacklist"}
+
+	// 1.19.0.0/16 is still listed, 2.0.0.0/8 is gone from the feed
+	repository.On("GetSourceNetworks", "blacklist", "drop").Return([]database.Entry{
+		{Network: "1.19.0.0/16", Source: "drop"},
+		{Network: "2.0.0.0/8", Source: "drop"},
+	}, nil)
+	repository.On("SyncNetworks", "blacklist", "drop",
+		[]database.Entry{{Network: "1.10.16.0/20", Metadata: database.Metadata{Ticket: "SBL256894"}}},
+		[]string{"2.0.0.0/8"},
+	).Return(database.ImportResult{Imported: 1, Removed: 1}, nil)
+
+	result, err := syncer.Sync(context.Background(), feed)
+	require.NoError(t, err)
+	assert.Equal(t, database.ImportResult{Imported: 1, Removed: 1}, result)
+	repository.AssertExpectations(t)
+}
+
+func TestSyncWithoutChanges(t *testing.T) {
+	syncer, repository := newSyncer(t)
+	path := filepath.Join(t.TempDir(), "list.netset")
+	require.NoError(t, os.WriteFile(path, []byte("# FireHOL netset\n10.0.0.0/8\n192.168.1.1\n"), 0o600))
+	feed := feeds.Feed{Name: "local", Path: path, Format: feeds.Netset, List: "whitelist"}
+
+	repository.On("GetSourceNetworks", "whitelist", "local").Return(entries("10.0.0.0/8", "192.168.1.1/32"), nil)
+
+	result, err := syncer.Sync(context.Background(), feed)
+	require.NoError(t, err)
+	assert.Zero(t, result)
+	repository.AssertNotCalled(t, "SyncNetworks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSyncKeepsEntriesOfEmptyFeed(t *testing.T) {
+	syncer, repository := newSyncer(t)
+	feed := feeds.Feed{Name: "plain", URL: serve(t, "# nothing today\n"), List: "blacklist"}
+	repository.On("GetSourceNetworks", "blacklist", "plain").Return(entries("10.0.0.0/8"), nil)
+
+	_, err := syncer.Sync(context.Background(), feed)
+	assert.Error(t, err)
+	repository.AssertNotCalled(t, "SyncNetworks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSyncFailsOnUnavailableFeed(t *testing.T) {
+	syncer, repository := newSyncer(t)
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := syncer.Sync(context.Background(), feeds.Feed{Name: "missing", URL: srv.URL, List: "blacklist"})
+	assert.Error(t, err)
+
+	_, err = syncer.Sync(context.Background(), feeds.Feed{Name: "missing", Path: filepath.Join(t.TempDir(), "missing"), List: "blacklist"})
+	assert.Error(t, err)
+	repository.AssertNotCalled(t, "GetSourceNetworks", mock.Anything, mock.Anything)
+}
+
+func TestNewSyncerSyncsFeeds(t *testing.T) {
+	repository := new(iplists.MockRepository)
+	synced := make(chan struct{})
+	repository.On("GetSourceNetworks", "blacklist", "plain").Return(nil, nil)
+	repository.On("SyncNetworks", "blacklist", "plain", entries("10.0.0.0/8"), []string{}).
+		Return(database.ImportResult{Imported: 1}, nil).
+		Run(func(mock.Arguments) { close(synced) })
+
+	feed := feeds.Feed{Name: "plain", URL: serve(t, "10.0.0.0/8\n"), List: "blacklist"}
+	syncer, err := feeds.NewSyncer(logruslogger.NewLogrusLogger("panic"), repository, http.DefaultClient, []feeds.Feed{feed})
+	require.NoError(t, err)
+	<-synced
+	require.NoError(t, syncer.Close())
+}
+
+func TestNewSyncerChecksFeeds(t *testing.T) {
+	valid := feeds.Feed{Name: "plain", Path: "/etc/feed", List: "blacklist"}
+	for _, invalid := range []feeds.Feed{
+		{Path: "/etc/feed", List: "blacklist"},
+		{Name: "plain", List: "blacklist"},
+		{Name: "plain", Path: "/etc/feed", URL: "http://feed", List: "blacklist"},
+		{Name: "plain", Path: "/etc/feed", Format: "xml", List: "blacklist"},
+		{Name: "plain", Path: "/etc/feed", List: "greylist"},
+	} {
+		assert.Error(t, invalid.Validate(), invalid)
+	}
+	require.NoError(t, valid.Validate())
+
+	_, err := feeds.NewSyncer(logruslogger.NewLogrusLogger("panic"), new(iplists.MockRepository), http.DefaultClient, []feeds.Feed{valid, valid})
+	assert.Error(t, err)
+}
+
+func entries(networks ...string) []database.Entry {
+	result := make([]database.Entry, len(networks))
+	for i, network := range networks {
+		result[i] = database.Entry{Network: network}
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package healthmonitor
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	healthiface "github.com/TheJubadze/RateLimiter/interfaces/health"
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Monitor serves the standard gRPC health checking service. Every service
+// of the server is SERVING while all the dependencies are reachable, and
+// NOT_SERVING as soon as one of them is not. The dependencies are pinged
+// when the monitor is created and then in the background.
+type Monitor struct {
+	logger   logger.Logger
+	checkers map[string]healthiface.Checker
+	timeout  time.Duration
+	server   *health.Server
+	done     chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	services []string
+	// unreachable are the dependencies the last check failed to reach
+	unreachable map[string]bool
+}
+
+// NewMonitor pings the dependencies, named by the keys of checkers, every
+// interval, giving up on a ping after timeout. The dependencies are pinged
+// concurrently, each with its own timeout, so a slow one doesn't make the
+// others look unreachable.
+func NewMonitor(logger logger.Logger, checkers map[string]healthiface.Checker, interval, timeout time.Duration) *Monitor {
+	m := &Monitor{
+		logger:      logger,
+		checkers:    checkers,
+		timeout:     timeout,
+		server:      health.NewServer(),
+		done:        make(chan struct{}),
+		services:    []string{""},
+		unreachable: make(map[string]bool),
+	}
+	m.Check()
+
+	m.wg.Add(1)
+	go m.checkLoop(interval)
+
+	return m
+}
+
+// Register registers the health service on the server, reporting the
+// status of every service registered on it so far and of the server as a
+// whole.
+func (m *Monitor) Register(server *grpc.Server) {
+	healthpb.RegisterHealthServer(server, m.server)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for service := range server.GetServiceInfo() {
+		m.services = append(m.services, service)
+	}
+	m.setStatus()
+}
+
+// Check pings every dependency and updates the status of the services.
+func (m *Monitor) Check() {
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		unreachable = make(map[string]error)
+	)
+	for name, checker := range m.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+			defer cancel()
+			if err := checker.Ping(ctx); err != nil {
+				mu.Lock()
+				unreachable[name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range sortedNames(m.checkers) {
+		err, down := unreachable[name]
+		switch {
+		case down && !m.unreachable[name]:
+			m.logger.Printf("Health check: %s is unreachable: %v", name, err)
+		case !down && m.unreachable[name]:
+			m.logger.Printf("Health check: %s is reachable again", name)
+		}
+		m.unreachable[name] = down
+	}
+	m.setStatus()
+}
+
+// Shutdown makes every service NOT_SERVING for good, so the clients stop
+// sending calls to a server about to stop.
+func (m *Monitor) Shutdown() {
+	m.server.Shutdown()
+}
+
+// Close stops checking the dependencies.
+func (m *Monitor) Close() {
+	close(m.done)
+	m.wg.Wait()
+}
+
+// setStatus sets the status of every service from the last check.
+// It must be called with the lock held.
+func (m *Monitor) setStatus() {
+	status := healthpb.HealthCheckResponse_SERVING
+	for _, down := range m.unreachable {
+		if down {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	for _, service := range m.services {
+		m.server.SetServingStatus(service, status)
+	}
+}
+
+func (m *Monitor) checkLoop(interval time.Duration) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// sortedNames returns the names of the dependencies in order, so they are
+// logged in the same order at every check.
+func sortedNames(checkers map[string]healthiface.Checker) []string {
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,108 @@
+package healthmonitor_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/health"
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/health"
+	"github.com/TheJubadze/RateLimiter/proto/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, monitor *healthmonitor.Monitor) healthpb.HealthClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterRateLimiterServer(server, pb.UnimplementedRateLimiterServer{})
+	monitor.Register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func status(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return response.Status
+}
+
+func TestMonitor(t *testing.T) {
+	redis, postgres := &health.FakeChecker{}, &health.FakeChecker{}
+	monitor := healthmonitor.NewMonitor(logruslogger.NewLogrusLogger("panic"),
+		map[string]health.Checker{"redis": redis, "postgres": postgres}, time.Hour, time.Second)
+	defer monitor.Close()
+	client := newClient(t, monitor)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status(t, client, pb.RateLimiter_ServiceDesc.ServiceName))
+
+	postgres.SetError(errors.New("connection refused"))
+	monitor.Check()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, client, pb.RateLimiter_ServiceDesc.ServiceName))
+
+	postgres.SetError(nil)
+	monitor.Check()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status(t, client, ""))
+}
+
+func TestMonitorGivesEveryPingItsOwnTimeout(t *testing.T) {
+	// Together, the pings take longer than the timeout
+	redis, postgres := &health.FakeChecker{}, &health.FakeChecker{}
+	redis.SetDelay(50 * time.Millisecond)
+	postgres.SetDelay(50 * time.Millisecond)
+	monitor := healthmonitor.NewMonitor(logruslogger.NewLogrusLogger("panic"),
+		map[string]health.Checker{"redis": redis, "postgres": postgres}, time.Hour, 90*time.Millisecond)
+	defer monitor.Close()
+	client := newClient(t, monitor)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status(t, client, ""))
+
+	redis.SetDelay(time.Second)
+	monitor.Check()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, client, ""))
+}
+
+func TestMonitorChecksInBackground(t *testing.T) {
+	redis := &health.FakeChecker{}
+	redis.SetError(errors.New("connection refused"))
+	monitor := healthmonitor.NewMonitor(logruslogger.NewLogrusLogger("panic"),
+		map[string]health.Checker{"redis": redis}, 10*time.Millisecond, time.Second)
+	defer monitor.Close()
+	client := newClient(t, monitor)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, client, ""))
+
+	redis.SetError(nil)
+	assert.Eventually(t, func() bool {
+		return status(t, client, "") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestMonitorShutdown(t *testing.T) {
+	monitor := healthmonitor.NewMonitor(logruslogger.NewLogrusLogger("panic"),
+		map[string]health.Checker{"redis": &health.FakeChecker{}}, time.Hour, time.Second)
+	defer monitor.Close()
+	client := newClient(t, monitor)
+
+	monitor.Shutdown()
+	monitor.Check()
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, client, pb.RateLimiter_ServiceDesc.ServiceName))
+}
@@ -1,95 +1,647 @@
 package ipfilter
 
 import (
+	"context"
 	"fmt"
-	"net"
+	"net/netip"
+	"sync"
+	"time"
 
 	"github.com/TheJubadze/RateLimiter/infrastructure/storage/iplists"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	ipfilteriface "github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
 	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/TheJubadze/RateLimiter/internal/iptrie"
 )
 
+const (
+	whitelist = "whitelist"
+	blacklist = "blacklist"
+)
+
+// Service keeps both lists in memory, so checking an IP needs no database
+// round trip. The lists are loaded when the service is created and updated
+// as networks are added and removed through it, or by other replicas once
+// it watches for changes.
+// Without the cache, IPs are checked with a containment query on the
+// database instead, and the lists are only loaded to look for overlaps.
+// Networks may be listed for a limited time. Expired ones no longer match,
+// and are deleted in the background.
+// Every listed network has a rule, and of the rules matching an IP, the one
+// of highest priority decides, then the one the precedence puts first.
 type Service struct {
+	logger     logger.Logger
+	clock      clock.Clock
 	repository iplists.Repository
+	watcher    iplists.Watcher
+	cached     bool
+	precedence ipfilteriface.Precedence
+	done       chan struct{}
+	wg         sync.WaitGroup
+	mu         sync.RWMutex
+	// lists map every listed network to its rule, if cached
+	lists map[string]*iptrie.Trie[rule]
+}
+
+// rule is the rule of a listed network and when it expires.
+type rule struct {
+	expiresAt time.Time
+	database.Rule
+}
+
+// candidate is a rule matching an IP.
+type candidate struct {
+	list   string
+	prefix netip.Prefix
+	rule   database.Rule
 }
 
-func NewService(connString string) (*Service, error) {
-	repo, err := iplistsrepository.NewRepository(connString)
+// NewService creates the service over the repository of the lists in the
+// database at connString, and takes ownership of the repository, even if
+// it fails. If cache is set, the lists are held in memory and kept current
+// with the changes made by every replica.
+// If sweepInterval is positive, expired networks are deleted at that interval.
+func NewService(logger logger.Logger, clock clock.Clock, repo iplists.Repository, connString string, sweepInterval time.Duration, cache bool, precedence ipfilteriface.Precedence) (*Service, error) {
+	if !cache {
+		s, err := NewServiceWithRepository(logger, clock, repo, sweepInterval, false, precedence)
+		if err != nil {
+			_ = repo.Close()
+			return nil, err
+		}
+		return s, nil
+	}
+
+	// Listen before loading the lists, so no change made in between is missed
+	watcher, err := iplistsrepository.NewWatcher(logger, connString)
 	if err != nil {
+		_ = repo.Close()
 		return nil, err
 	}
-	return &Service{repository: repo}, nil
+
+	s, err := NewServiceWithRepository(logger, clock, repo, sweepInterval, true, precedence)
+	if err != nil {
+		_ = watcher.Close()
+		_ = repo.Close()
+		return nil, err
+	}
+	s.Watch(watcher)
+
+	return s, nil
 }
 
+// NewServiceWithRepository creates the service over the given repository
+// and, if cache is set, loads the lists from it.
+// If sweepInterval is positive, expired networks are deleted at that interval.
+func NewServiceWithRepository(logger logger.Logger, clock clock.Clock, repository iplists.Repository, sweepInterval time.Duration, cache bool, precedence ipfilteriface.Precedence) (*Service, error) {
+	switch precedence {
+	case ipfilteriface.ListPrecedence, ipfilteriface.SpecificityPrecedence:
+	default:
+		return nil, fmt.Errorf("unknown rule precedence: %q", precedence)
+	}
+
+	s := &Service{
+		logger:     logger,
+		clock:      clock,
+		repository: repository,
+		cached:     cache,
+		precedence: precedence,
+		done:       make(chan struct{}),
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	if sweepInterval > 0 {
+		s.wg.Add(1)
+		go s.sweepLoop(sweepInterval)
+	}
+
+	return s, nil
+}
+
+// Watch applies the changes delivered by the watcher until it is closed,
+// and reloads the lists whenever changes may have been missed.
+// Close closes the watcher.
+func (s *Service) Watch(watcher iplists.Watcher) {
+	s.watcher = watcher
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for change := range watcher.Changes() {
+			s.apply(change)
+		}
+	}()
+}
+
+// Reload replaces the lists held in memory with the ones in the repository.
+// It does nothing without the cache.
+func (s *Service) Reload() error {
+	if !s.cached {
+		return nil
+	}
+
+	lists, err := s.loadLists()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.lists = lists
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Service) loadLists() (map[string]*iptrie.Trie[rule], error) {
+	lists := make(map[string]*iptrie.Trie[rule], 2)
+	for _, table := range []string{whitelist, blacklist} {
+		entries, err := s.repository.GetNetworks(table)
+		if err != nil {
+			return nil, err
+		}
+
+		trie := iptrie.New[rule]()
+		for _, entry := range entries {
+			prefix, err := netip.ParsePrefix(entry.Network)
+			if err != nil {
+				continue
+			}
+			trie.Insert(prefix, rule{expiresAt: entry.ExpiresAt, Rule: ruleOf(table, entry.Rule)})
+		}
+		lists[table] = trie
+	}
+	return lists, nil
+}
+
+// readLists calls fn with the lists held in memory, or without the cache,
+// with the lists loaded from the repository for the call.
+func (s *Service) readLists(fn func(lists map[string]*iptrie.Trie[rule])) error {
+	if !s.cached {
+		lists, err := s.loadLists()
+		if err != nil {
+			return err
+		}
+		fn(lists)
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.lists)
+	return nil
+}
+
+// Close stops watching for changes and sweeping, and closes the repository.
 func (s *Service) Close() error {
+	close(s.done)
+	if s.watcher != nil {
+		if err := s.watcher.Close(); err != nil {
+			s.logger.Printf("Failed to close IP lists watcher: %v", err)
+		}
+	}
+	s.wg.Wait()
 	return s.repository.Close()
 }
 
+// Evaluate returns the rule deciding about the requests from an IP, and
+// whether any rule matches it. Without the lists cached, it fails if the
+// database does, rather than letting a blacklisted IP through.
+func (s *Service) Evaluate(ctx context.Context, ip string) (ipfilteriface.Decision, bool, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ipfilteriface.Decision{}, false, nil
+	}
+	addr = addr.WithZone("")
+
+	var candidates []candidate
+	if s.cached {
+		now := s.clock.Now()
+		s.mu.RLock()
+		for _, table := range []string{whitelist, blacklist} {
+			s.lists[table].Match(addr, func(prefix netip.Prefix, r rule) bool {
+				if !expired(r.expiresAt, now) {
+					candidates = append(candidates, candidate{list: table, prefix: prefix, rule: r.Rule})
+				}
+				return false
+			})
+		}
+		s.mu.RUnlock()
+	} else {
+		for _, table := range []string{whitelist, blacklist} {
+			entries, err := s.repository.GetContainingNetworks(ctx, table, addr.String())
+			if err != nil {
+				return ipfilteriface.Decision{}, false, fmt.Errorf("failed to check %s against the %s: %w", ip, table, err)
+			}
+			for _, entry := range entries {
+				prefix, err := netip.ParsePrefix(entry.Network)
+				if err != nil {
+					continue
+				}
+				candidates = append(candidates, candidate{list: table, prefix: prefix, rule: ruleOf(table, entry.Rule)})
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ipfilteriface.Decision{}, false, nil
+	}
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if s.precedes(c, best) {
+			best = c
+		}
+	}
+	return ipfilteriface.Decision{List: best.list, Network: best.prefix.String(), Rule: best.rule}, true, nil
+}
+
+// precedes reports whether rule a decides over rule b.
+func (s *Service) precedes(a, b candidate) bool {
+	if a.rule.Priority != b.rule.Priority {
+		return a.rule.Priority > b.rule.Priority
+	}
+	if s.precedence == ipfilteriface.SpecificityPrecedence {
+		if a.prefix.Bits() != b.prefix.Bits() {
+			return a.prefix.Bits() > b.prefix.Bits()
+		}
+		return a.list == whitelist && b.list != whitelist
+	}
+	if a.list != b.list {
+		return a.list == whitelist
+	}
+	return a.prefix.Bits() > b.prefix.Bits()
+}
+
 func (s *Service) IsIPWhitelisted(ip string) bool {
-	return s.isIPListed(ip, true)
+	return s.isIPListed(whitelist, ip)
 }
 
 func (s *Service) IsIPBlacklisted(ip string) bool {
-	return s.isIPListed(ip, false)
+	return s.isIPListed(blacklist, ip)
 }
 
 func (s *Service) IsNetworkWhitelisted(network string) (bool, error) {
-	return s.repository.IsNetworkExists("whitelist", network)
+	return s.repository.IsNetworkExists(whitelist, network)
 }
 
 func (s *Service) IsNetworkBlacklisted(network string) (bool, error) {
-	return s.repository.IsNetworkExists("blacklist", network)
+	return s.repository.IsNetworkExists(blacklist, network)
 }
 
-func (s *Service) AddToWhitelist(subnet string) error {
-	return s.repository.InsertNetwork("whitelist", subnet)
+// AddToWhitelist whitelists a network for ttl, or for good if ttl is zero.
+func (s *Service) AddToWhitelist(subnet string, ttl time.Duration, rule database.Rule, metadata database.Metadata) error {
+	return s.addNetwork(whitelist, subnet, ttl, rule, metadata)
 }
 
 func (s *Service) RemoveFromWhitelist(subnet string) (bool, error) {
-	return s.repository.DeleteNetwork("whitelist", subnet)
+	return s.removeNetwork(whitelist, subnet)
 }
 
-func (s *Service) AddToBlacklist(subnet string) error {
-	return s.repository.InsertNetwork("blacklist", subnet)
+// AddToBlacklist blacklists a network for ttl, or for good if ttl is zero.
+func (s *Service) AddToBlacklist(subnet string, ttl time.Duration, rule database.Rule, metadata database.Metadata) error {
+	return s.addNetwork(blacklist, subnet, ttl, rule, metadata)
 }
 
 func (s *Service) RemoveFromBlacklist(subnet string) (bool, error) {
-	return s.repository.DeleteNetwork("blacklist", subnet)
+	return s.removeNetwork(blacklist, subnet)
 }
 
-func (s *Service) isIPListed(ip string, isWhitelist bool) bool {
-	table := "blacklist"
-	if isWhitelist {
-		table = "whitelist"
+// ImportWhitelist whitelists the networks, or updates them if they already
+// are, all at once. If replace is set, the networks not imported are
+// removed from the whitelist.
+func (s *Service) ImportWhitelist(entries []database.Entry, replace bool) (database.ImportResult, error) {
+	return s.importNetworks(whitelist, entries, replace)
+}
+
+// ImportBlacklist blacklists the networks, or updates them if they already
+// are, all at once. If replace is set, the networks not imported are
+// removed from the blacklist.
+func (s *Service) ImportBlacklist(entries []database.Entry, replace bool) (database.ImportResult, error) {
+	return s.importNetworks(blacklist, entries, replace)
+}
+
+// importNetworks imports the networks and loads the lists again, rather
+// than applying what may be thousands of changes one by one.
+func (s *Service) importNetworks(table string, entries []database.Entry, replace bool) (database.ImportResult, error) {
+	result, err := s.repository.ImportNetworks(table, entries, replace)
+	if err != nil {
+		return database.ImportResult{}, err
 	}
 
-	rows, err := s.repository.GetNetworks(table)
+	if err := s.Reload(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ListWhitelist returns a page of the whitelisted networks, read from the
+// repository along with their details.
+func (s *Service) ListWhitelist(query database.ListQuery) (database.Page, error) {
+	return s.repository.ListNetworks(whitelist, query)
+}
+
+// ListBlacklist returns a page of the blacklisted networks, read from the
+// repository along with their details.
+func (s *Service) ListBlacklist(query database.ListQuery) (database.Page, error) {
+	return s.repository.ListNetworks(blacklist, query)
+}
+
+// Inspect returns the listed networks an IP or network falls into,
+// whitelist first, shortest prefix first. The networks are matched in memory
+// and their details read from the repository, or without the cache, both
+// are read from the repository.
+func (s *Service) Inspect(network string) ([]ipfilteriface.Entry, error) {
+	prefix, err := parseNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.cached {
+		var entries []ipfilteriface.Entry
+		for _, table := range []string{whitelist, blacklist} {
+			listed, err := s.repository.GetContainingNetworks(context.Background(), table, prefix.String())
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range listed {
+				entries = append(entries, ipfilteriface.Entry{List: table, Entry: entry})
+			}
+		}
+		return entries, nil
+	}
+
+	now := s.clock.Now()
+	matches := make(map[string][]netip.Prefix, 2)
+	s.mu.RLock()
+	for _, table := range []string{whitelist, blacklist} {
+		s.lists[table].Match(prefix.Addr(), func(listed netip.Prefix, r rule) bool {
+			if listed.Bits() <= prefix.Bits() && !expired(r.expiresAt, now) {
+				matches[table] = append(matches[table], listed)
+			}
+			return false
+		})
+	}
+	s.mu.RUnlock()
+
+	var entries []ipfilteriface.Entry
+	for _, table := range []string{whitelist, blacklist} {
+		for _, listed := range matches[table] {
+			entry, ok, err := s.repository.GetNetwork(table, listed.String())
+			if err != nil {
+				return nil, err
+			}
+			// The network may have been removed in the meantime
+			if ok {
+				entries = append(entries, ipfilteriface.Entry{List: table, Entry: entry})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// FindOverlaps returns how a network would overlap the listed networks if
+// it was added to the list, leaving out the network itself.
+func (s *Service) FindOverlaps(list, network string) ([]ipfilteriface.Overlap, error) {
+	prefix, err := parseNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	var overlaps []ipfilteriface.Overlap
+	err = s.readLists(func(lists map[string]*iptrie.Trie[rule]) {
+		overlaps = findOverlaps(lists, ipfilteriface.ListedNetwork{List: list, Network: prefix.String()}, prefix, now)
+	})
+	return overlaps, err
+}
+
+// CheckConsistency returns every pair of overlapping listed networks.
+func (s *Service) CheckConsistency() ([]ipfilteriface.Overlap, error) {
+	now := s.clock.Now()
+	var overlaps []ipfilteriface.Overlap
+	err := s.readLists(func(lists map[string]*iptrie.Trie[rule]) {
+		// Every pair is found from its subnet, so it is found once
+		for _, table := range []string{whitelist, blacklist} {
+			lists[table].Walk(func(prefix netip.Prefix, r rule) {
+				if expired(r.expiresAt, now) {
+					return
+				}
+				subnet := ipfilteriface.ListedNetwork{List: table, Network: prefix.String()}
+				for _, overlap := range findSupernets(lists, subnet, prefix, now) {
+					// A network on both lists is found from both, it is kept once
+					if overlap.Supernet.Network == subnet.Network && table == whitelist {
+						continue
+					}
+					overlaps = append(overlaps, overlap)
+				}
+			})
+		}
+	})
+	return overlaps, err
+}
+
+// findOverlaps returns the overlaps between a network and the listed ones,
+// other than the network itself.
+func findOverlaps(lists map[string]*iptrie.Trie[rule], network ipfilteriface.ListedNetwork, prefix netip.Prefix, now time.Time) []ipfilteriface.Overlap {
+	overlaps := findSupernets(lists, network, prefix, now)
+	for _, table := range []string{whitelist, blacklist} {
+		lists[table].WalkSubnets(prefix, func(listed netip.Prefix, r rule) {
+			if listed != prefix && !expired(r.expiresAt, now) {
+				overlaps = append(overlaps, ipfilteriface.Overlap{
+					Supernet: network,
+					Subnet:   ipfilteriface.ListedNetwork{List: table, Network: listed.String()},
+				})
+			}
+		})
+	}
+	return overlaps
+}
+
+// findSupernets returns the overlaps between a network and the listed
+// networks holding it, other than the network itself.
+func findSupernets(lists map[string]*iptrie.Trie[rule], network ipfilteriface.ListedNetwork, prefix netip.Prefix, now time.Time) []ipfilteriface.Overlap {
+	var overlaps []ipfilteriface.Overlap
+	for _, table := range []string{whitelist, blacklist} {
+		lists[table].Match(prefix.Addr(), func(listed netip.Prefix, r rule) bool {
+			if listed.Bits() <= prefix.Bits() && !expired(r.expiresAt, now) && (table != network.List || listed != prefix) {
+				overlaps = append(overlaps, ipfilteriface.Overlap{
+					Supernet: ipfilteriface.ListedNetwork{List: table, Network: listed.String()},
+					Subnet:   network,
+				})
+			}
+			return false
+		})
+	}
+	return overlaps
+}
+
+func (s *Service) addNetwork(table, subnet string, ttl time.Duration, r database.Rule, metadata database.Metadata) error {
+	if ttl < 0 {
+		return fmt.Errorf("invalid TTL: %s", ttl)
+	}
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return err
+	}
+
+	entry := database.Entry{Network: subnet, Metadata: metadata, Rule: ruleOf(table, r)}
+	if ttl > 0 {
+		entry.ExpiresAt = s.clock.Now().Add(ttl)
+	}
+	if err := s.repository.InsertNetwork(table, entry); err != nil {
+		return err
+	}
+	if !s.cached {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.lists[table].Insert(prefix, rule{expiresAt: entry.ExpiresAt, Rule: entry.Rule})
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Service) removeNetwork(table, subnet string) (bool, error) {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := s.repository.DeleteNetwork(table, subnet)
+	if err != nil || !removed || !s.cached {
+		return removed, err
+	}
+
+	s.mu.Lock()
+	s.lists[table].Remove(prefix)
+	s.mu.Unlock()
+	return true, nil
+}
+
+// apply updates the lists with a change made by this or another replica.
+// Changes are idempotent, so ones already applied locally do no harm.
+func (s *Service) apply(change iplists.Change) {
+	if change.Kind == iplists.ChangesLost {
+		if err := s.Reload(); err != nil {
+			s.logger.Printf("Failed to reload IP lists: %v", err)
+			return
+		}
+		s.logger.Printf("Reloaded IP lists")
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(change.Network)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, ok := s.lists[change.Table]
+	if !ok {
+		return
+	}
+	switch change.Kind {
+	case iplists.NetworkInserted:
+		list.Insert(prefix, rule{expiresAt: change.ExpiresAt, Rule: ruleOf(change.Table, change.Rule)})
+	case iplists.NetworkDeleted:
+		list.Remove(prefix)
+	}
+}
+
+func (s *Service) isIPListed(table, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
 	if err != nil {
 		return false
 	}
 
-	for _, cidr := range rows {
-		inSubnet, err := isIPInSubnet(ip, cidr)
+	if !s.cached {
+		entries, err := s.repository.GetContainingNetworks(context.Background(), table, addr.WithZone("").String())
 		if err != nil {
-			continue
+			s.logger.Printf("Failed to check %s against the %s: %v", ip, table, err)
+			return false
 		}
-		if inSubnet {
-			return true
+		return len(entries) > 0
+	}
+
+	now := s.clock.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lists[table].Match(addr.WithZone(""), func(_ netip.Prefix, r rule) bool {
+		return !expired(r.expiresAt, now)
+	})
+}
+
+func (s *Service) sweepLoop(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.sweep()
 		}
 	}
+}
 
-	return false
+// sweep deletes the expired networks from the database and from memory.
+// Every replica sweeps, deleting what another one already did is a no-op.
+func (s *Service) sweep() {
+	for _, table := range []string{whitelist, blacklist} {
+		deleted, err := s.repository.DeleteExpiredNetworks(table)
+		if err != nil {
+			s.logger.Printf("Failed to delete expired networks from the %s: %v", table, err)
+		} else if deleted > 0 {
+			s.logger.Printf("Deleted %d expired networks from the %s", deleted, table)
+		}
+	}
+
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, list := range s.lists {
+		var prefixes []netip.Prefix
+		list.Walk(func(prefix netip.Prefix, r rule) {
+			if expired(r.expiresAt, now) {
+				prefixes = append(prefixes, prefix)
+			}
+		})
+		for _, prefix := range prefixes {
+			list.Remove(prefix)
+		}
+	}
 }
 
-func isIPInSubnet(ipStr string, cidr string) (bool, error) {
-	_, subnet, err := net.ParseCIDR(cidr)
+// parseNetwork parses a network, or an IP as the network holding only it.
+func parseNetwork(network string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(network)
 	if err != nil {
-		return false, fmt.Errorf("invalid CIDR: %w", err)
+		addr, addrErr := netip.ParseAddr(network)
+		if addrErr != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.WithZone("")
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
 	}
 
-	ip := net.ParseIP(ipStr)
-	if ip == nil {
-		return false, fmt.Errorf("invalid IP address: %v", ipStr)
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
 	}
+	return prefix.Masked(), nil
+}
+
+// ruleOf returns a rule with the default action of the list if it has none.
+func ruleOf(table string, r database.Rule) database.Rule {
+	if r.Action == "" {
+		r.Action = iplists.DefaultAction(table)
+	}
+	return r
+}
 
-	return subnet.Contains(ip), nil
+func expired(expiresAt, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
 }
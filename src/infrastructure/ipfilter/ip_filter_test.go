@@ -0,0 +1,440 @@
+package ipfilter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/ipfilter"
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	ipfilteriface "github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Unix(1700000000, 0)
+
+func entries(networks ...string) []database.Entry {
+	result := make([]database.Entry, len(networks))
+	for i, network := range networks {
+		result[i] = database.Entry{Network: network}
+	}
+	return result
+}
+
+func newService(t *testing.T, whitelist, blacklist []database.Entry) (*ipfilter.Service, *iplists.MockRepository, *clock.FakeClock) {
+	t.Helper()
+	repository := new(iplists.MockRepository)
+	repository.On("GetNetworks", "whitelist").Return(whitelist, nil).Once()
+	repository.On("GetNetworks", "blacklist").Return(blacklist, nil).Once()
+
+	fakeClock := clock.NewFakeClock(now)
+	service, err := ipfilter.NewServiceWithRepository(logruslogger.NewLogrusLogger("panic"), fakeClock, repository, 0, true, ipfilteriface.ListPrecedence)
+	require.NoError(t, err)
+	return service, repository, fakeClock
+}
+
+func TestIsIPListed(t *testing.T) {
+	service, repository, _ := newService(t, entries("192.168.1.0/24", "2001:db8::/32"), entries("10.0.0.0/8"))
+
+	assert.True(t, service.IsIPWhitelisted("192.168.1.10"))
+	assert.True(t, service.IsIPWhitelisted("2001:db8::1"))
+	assert.False(t, service.IsIPWhitelisted("10.0.0.1"))
+	assert.True(t, service.IsIPBlacklisted("10.0.0.1"))
+	assert.False(t, service.IsIPBlacklisted("192.168.1.10"))
+	assert.False(t, service.IsIPBlacklisted("not an ip"))
+
+	// Lookups don't go to the database
+	repository.AssertNumberOfCalls(t, "GetNetworks", 2)
+}
+
+func TestEvaluate(t *testing.T) {
+	whitelist := []database.Entry{
+		{Network: "10.0.0.0/8"},
+		{Network: "10.1.2.0/24", Rule: database.Rule{Action: database.BypassIPLimit}},
+		{Network: "172.16.0.0/12", Rule: database.Rule{Action: database.Limit}},
+	}
+	blacklist := []database.Entry{
+		{Network: "10.1.0.0/16"},
+		{Network: "10.9.9.9/32", Rule: database.Rule{Priority: 1}},
+		{Network: "172.16.1.0/24"},
+		{Network: "192.168.0.0/16", ExpiresAt: now.Add(-time.Minute)},
+	}
+
+	tests := []struct {
+		precedence ipfilteriface.Precedence
+		ip         string
+		expected   ipfilteriface.Decision
+	}{
+		// The whitelist decides over the blacklist, then the most specific network
+		{ipfilteriface.ListPrecedence, "10.1.3.4", decision("whitelist", "10.0.0.0/8", database.Allow, 0)},
+		{ipfilteriface.ListPrecedence, "10.1.2.3", decision("whitelist", "10.1.2.0/24", database.BypassIPLimit, 0)},
+		{ipfilteriface.ListPrecedence, "172.16.1.1", decision("whitelist", "172.16.0.0/12", database.Limit, 0)},
+		// The most specific network decides, then the whitelist
+		{ipfilteriface.SpecificityPrecedence, "10.1.3.4", decision("blacklist", "10.1.0.0/16", database.Deny, 0)},
+		{ipfilteriface.SpecificityPrecedence, "10.1.2.3", decision("whitelist", "10.1.2.0/24", database.BypassIPLimit, 0)},
+		{ipfilteriface.SpecificityPrecedence, "172.16.1.1", decision("blacklist", "172.16.1.0/24", database.Deny, 0)},
+		// A higher priority decides over both
+		{ipfilteriface.ListPrecedence, "10.9.9.9", decision("blacklist", "10.9.9.9/32", database.Deny, 1)},
+		{ipfilteriface.SpecificityPrecedence, "10.9.9.9", decision("blacklist", "10.9.9.9/32", database.Deny, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.precedence)+" "+tt.ip, func(t *testing.T) {
+			repository := new(iplists.MockRepository)
+			repository.On("GetNetworks", "whitelist").Return(whitelist, nil)
+			repository.On("GetNetworks", "blacklist").Return(blacklist, nil)
+			service, err := ipfilter.NewServiceWithRepository(logruslogger.NewLogrusLogger("panic"), clock.NewFakeClock(now), repository, 0, true, tt.precedence)
+			require.NoError(t, err)
+
+			decided, ok, err := service.Evaluate(context.Background(), tt.ip)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, decided)
+		})
+	}
+
+	service, _, _ := newService(t, whitelist, blacklist)
+	for _, ip := range []string{"192.168.1.1", "8.8.8.8", "not an ip"} {
+		_, ok, err := service.Evaluate(context.Background(), ip)
+		require.NoError(t, err)
+		assert.False(t, ok, "%s matches no rule, expired rules don't match", ip)
+	}
+
+	_, err := ipfilter.NewServiceWithRepository(logruslogger.NewLogrusLogger("panic"), clock.NewFakeClock(now), new(iplists.MockRepository), 0, true, "loudest")
+	assert.Error(t, err)
+}
+
+func TestAddAndRemoveUpdateLists(t *testing.T) {
+	service, repository, _ := newService(t, nil, nil)
+	repository.On("InsertNetwork", "blacklist", database.Entry{Network: "172.16.0.0/12", Rule: database.Rule{Action: database.Deny}}).Return(nil)
+	repository.On("DeleteNetwork", "blacklist", "172.16.0.0/12").Return(true, nil)
+
+	require.NoError(t, service.AddToBlacklist("172.16.0.0/12", 0, database.Rule{}, database.Metadata{}))
+	assert.True(t, service.IsIPBlacklisted("172.20.1.1"))
+	assert.False(t, service.IsIPWhitelisted("172.20.1.1"))
+
+	removed, err := service.RemoveFromBlacklist("172.16.0.0/12")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.False(t, service.IsIPBlacklisted("172.20.1.1"))
+	repository.AssertExpectations(t)
+}
+
+func TestFailedWriteKeepsLists(t *testing.T) {
+	service, repository, _ := newService(t, entries("192.168.1.0/24"), nil)
+	repository.On("InsertNetwork", "whitelist", mock.Anything).Return(errors.New("connection refused"))
+	repository.On("DeleteNetwork", "whitelist", "192.168.1.0/24").Return(false, errors.New("connection refused"))
+
+	assert.Error(t, service.AddToWhitelist("10.0.0.0/8", 0, database.Rule{}, database.Metadata{}))
+	assert.False(t, service.IsIPWhitelisted("10.0.0.1"))
+
+	_, err := service.RemoveFromWhitelist("192.168.1.0/24")
+	assert.Error(t, err)
+	assert.True(t, service.IsIPWhitelisted("192.168.1.1"))
+}
+
+func TestAddWithTTL(t *testing.T) {
+	service, repository, fakeClock := newService(t, nil, nil)
+	metadata := database.Metadata{Reason: "abuse", CreatedBy: "alice", Ticket: "SEC-1", Labels: map[string]string{"source": "manual"}}
+	repository.On("InsertNetwork", "blacklist", database.Entry{
+		Network:   "10.0.0.0/8",
+		Metadata:  metadata,
+		Rule:      database.Rule{Action: database.Limit, Priority: 5},
+		ExpiresAt: now.Add(time.Hour),
+	}).Return(nil)
+
+	require.NoError(t, service.AddToBlacklist("10.0.0.0/8", time.Hour, database.Rule{Action: database.Limit, Priority: 5}, metadata))
+	assert.True(t, service.IsIPBlacklisted("10.0.0.1"))
+
+	fakeClock.Advance(time.Hour)
+	assert.False(t, service.IsIPBlacklisted("10.0.0.1"))
+
+	assert.Error(t, service.AddToBlacklist("10.0.0.0/8", -time.Hour, database.Rule{}, database.Metadata{}))
+	repository.AssertExpectations(t)
+}
+
+func TestInspect(t *testing.T) {
+	service, repository, fakeClock := newService(t,
+		entries("10.1.0.0/16"),
+		[]database.Entry{
+			{Network: "10.0.0.0/8"},
+			{Network: "10.1.2.0/24", ExpiresAt: now.Add(time.Hour)},
+			{Network: "192.168.0.0/16"},
+		})
+	wide := database.Entry{Network: "10.0.0.0/8", Metadata: database.Metadata{Reason: "abuse"}}
+	narrow := database.Entry{Network: "10.1.2.0/24", ExpiresAt: now.Add(time.Hour)}
+	allowed := database.Entry{Network: "10.1.0.0/16", Metadata: database.Metadata{CreatedBy: "alice"}}
+	repository.On("GetNetwork", "blacklist", "10.0.0.0/8").Return(wide, true, nil)
+	repository.On("GetNetwork", "blacklist", "10.1.2.0/24").Return(narrow, true, nil)
+	repository.On("GetNetwork", "whitelist", "10.1.0.0/16").Return(allowed, true, nil)
+
+	inspected, err := service.Inspect("10.1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Entry{
+		{List: "whitelist", Entry: allowed},
+		{List: "blacklist", Entry: wide},
+		{List: "blacklist", Entry: narrow},
+	}, inspected)
+
+	// A network only falls into the networks holding all of it
+	inspected, err = service.Inspect("10.1.0.0/16")
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Entry{
+		{List: "whitelist", Entry: allowed},
+		{List: "blacklist", Entry: wide},
+	}, inspected)
+
+	fakeClock.Advance(time.Hour)
+	inspected, err = service.Inspect("10.1.2.3")
+	require.NoError(t, err)
+	assert.Len(t, inspected, 2)
+
+	inspected, err = service.Inspect("172.16.0.1")
+	require.NoError(t, err)
+	assert.Empty(t, inspected)
+
+	_, err = service.Inspect("not an ip")
+	assert.Error(t, err)
+}
+
+func TestImportReloadsLists(t *testing.T) {
+	service, repository, _ := newService(t, nil, entries("10.0.0.0/8"))
+	imported := entries("192.168.0.0/16")
+	repository.On("ImportNetworks", "blacklist", imported, true).Return(database.ImportResult{Imported: 1, Removed: 1}, nil)
+	repository.On("GetNetworks", "whitelist").Return(nil, nil).Once()
+	repository.On("GetNetworks", "blacklist").Return(imported, nil).Once()
+
+	result, err := service.ImportBlacklist(imported, true)
+	require.NoError(t, err)
+	assert.Equal(t, database.ImportResult{Imported: 1, Removed: 1}, result)
+	assert.True(t, service.IsIPBlacklisted("192.168.1.1"))
+	assert.False(t, service.IsIPBlacklisted("10.0.0.1"))
+	repository.AssertExpectations(t)
+}
+
+func TestList(t *testing.T) {
+	service, repository, _ := newService(t, nil, nil)
+	query := database.ListQuery{Contains: "10.1.2.3", Limit: 10}
+	page := database.Page{Entries: entries("10.0.0.0/8")}
+	repository.On("ListNetworks", "blacklist", query).Return(page, nil)
+
+	listed, err := service.ListBlacklist(query)
+	require.NoError(t, err)
+	assert.Equal(t, page, listed)
+	repository.AssertExpectations(t)
+}
+
+func TestFindOverlaps(t *testing.T) {
+	service, _, _ := newService(t, entries("10.0.0.0/8", "192.168.1.0/24"), entries("10.1.0.0/16", "10.1.2.0/24", "172.16.0.0/12"))
+
+	overlaps, err := service.FindOverlaps("blacklist", "10.1.0.0/16")
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Overlap{
+		{Supernet: listed("whitelist", "10.0.0.0/8"), Subnet: listed("blacklist", "10.1.0.0/16")},
+		{Supernet: listed("blacklist", "10.1.0.0/16"), Subnet: listed("blacklist", "10.1.2.0/24")},
+	}, overlaps)
+
+	overlaps, err = service.FindOverlaps("whitelist", "192.168.0.0/16")
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Overlap{
+		{Supernet: listed("whitelist", "192.168.0.0/16"), Subnet: listed("whitelist", "192.168.1.0/24")},
+	}, overlaps)
+
+	overlaps, err = service.FindOverlaps("whitelist", "172.16.0.0/12")
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Overlap{
+		{Supernet: listed("blacklist", "172.16.0.0/12"), Subnet: listed("whitelist", "172.16.0.0/12")},
+	}, overlaps)
+
+	overlaps, err = service.FindOverlaps("blacklist", "8.8.8.8")
+	require.NoError(t, err)
+	assert.Empty(t, overlaps)
+
+	_, err = service.FindOverlaps("blacklist", "not a network")
+	assert.Error(t, err)
+}
+
+func TestCheckConsistency(t *testing.T) {
+	service, _, fakeClock := newService(t,
+		[]database.Entry{{Network: "10.0.0.0/8"}, {Network: "172.16.0.0/12"}, {Network: "192.168.0.0/16", ExpiresAt: now.Add(time.Hour)}},
+		entries("10.1.0.0/16", "10.1.2.0/24", "172.16.0.0/12", "192.168.1.0/24", "8.8.8.0/24"))
+
+	overlaps, err := service.CheckConsistency()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ipfilteriface.Overlap{
+		{Supernet: listed("whitelist", "10.0.0.0/8"), Subnet: listed("blacklist", "10.1.0.0/16")},
+		{Supernet: listed("whitelist", "10.0.0.0/8"), Subnet: listed("blacklist", "10.1.2.0/24")},
+		{Supernet: listed("blacklist", "10.1.0.0/16"), Subnet: listed("blacklist", "10.1.2.0/24")},
+		{Supernet: listed("whitelist", "172.16.0.0/12"), Subnet: listed("blacklist", "172.16.0.0/12")},
+		{Supernet: listed("whitelist", "192.168.0.0/16"), Subnet: listed("blacklist", "192.168.1.0/24")},
+	}, overlaps)
+
+	// Expired networks overlap nothing
+	fakeClock.Advance(time.Hour)
+	overlaps, err = service.CheckConsistency()
+	require.NoError(t, err)
+	assert.Len(t, overlaps, 4)
+}
+
+func TestUncachedServiceQueriesRepository(t *testing.T) {
+	repository := new(iplists.MockRepository)
+	service, err := ipfilter.NewServiceWithRepository(logruslogger.NewLogrusLogger("panic"), clock.NewFakeClock(now), repository, 0, false, ipfilteriface.ListPrecedence)
+	require.NoError(t, err)
+
+	blacklisted := database.Entry{Network: "10.0.0.0/8"}
+	repository.On("GetContainingNetworks", mock.Anything, "whitelist", "10.1.2.3").Return([]database.Entry(nil), nil)
+	repository.On("GetContainingNetworks", mock.Anything, "blacklist", "10.1.2.3").Return([]database.Entry{blacklisted}, nil)
+	repository.On("GetContainingNetworks", mock.Anything, "whitelist", "192.168.1.1").Return([]database.Entry(nil), errors.New("connection refused"))
+
+	assert.True(t, service.IsIPBlacklisted("10.1.2.3"))
+	assert.False(t, service.IsIPWhitelisted("10.1.2.3"))
+	assert.False(t, service.IsIPWhitelisted("192.168.1.1"))
+
+	decided, ok, err := service.Evaluate(context.Background(), "10.1.2.3")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, decision("blacklist", "10.0.0.0/8", database.Deny, 0), decided)
+
+	// A blacklisted IP is not let through while the database is down
+	_, _, err = service.Evaluate(context.Background(), "192.168.1.1")
+	assert.ErrorContains(t, err, "connection refused")
+
+	repository.On("GetContainingNetworks", mock.Anything, "whitelist", "10.1.2.3/32").Return([]database.Entry(nil), nil)
+	repository.On("GetContainingNetworks", mock.Anything, "blacklist", "10.1.2.3/32").Return([]database.Entry{blacklisted}, nil)
+
+	inspected, err := service.Inspect("10.1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Entry{{List: "blacklist", Entry: blacklisted}}, inspected)
+
+	repository.On("InsertNetwork", "whitelist", database.Entry{Network: "10.1.0.0/16", Rule: database.Rule{Action: database.Allow}}).Return(nil)
+	require.NoError(t, service.AddToWhitelist("10.1.0.0/16", 0, database.Rule{}, database.Metadata{}))
+
+	// The lists are only loaded to look for overlaps
+	repository.On("GetNetworks", "whitelist").Return(entries("10.1.0.0/16"), nil)
+	repository.On("GetNetworks", "blacklist").Return([]database.Entry{blacklisted}, nil)
+
+	overlaps, err := service.CheckConsistency()
+	require.NoError(t, err)
+	assert.Equal(t, []ipfilteriface.Overlap{
+		{Supernet: listed("blacklist", "10.0.0.0/8"), Subnet: listed("whitelist", "10.1.0.0/16")},
+	}, overlaps)
+	repository.AssertExpectations(t)
+}
+
+func TestLookupsIgnoreExpiredEntries(t *testing.T) {
+	service, _, fakeClock := newService(t, []database.Entry{
+		{Network: "10.0.0.0/8", ExpiresAt: now.Add(time.Minute)},
+		{Network: "10.1.0.0/16", ExpiresAt: now.Add(time.Hour)},
+		{Network: "192.168.0.0/16"},
+	}, nil)
+
+	assert.True(t, service.IsIPWhitelisted("10.2.0.1"))
+	fakeClock.Advance(time.Minute)
+	assert.False(t, service.IsIPWhitelisted("10.2.0.1"))
+	// A narrower network that has not expired still applies
+	assert.True(t, service.IsIPWhitelisted("10.1.0.1"))
+
+	fakeClock.Advance(time.Hour)
+	assert.False(t, service.IsIPWhitelisted("10.1.0.1"))
+	assert.True(t, service.IsIPWhitelisted("192.168.1.1"))
+}
+
+func TestSweepsExpiredEntries(t *testing.T) {
+	repository := new(iplists.MockRepository)
+	repository.On("GetNetworks", "whitelist").Return(nil, nil)
+	repository.On("GetNetworks", "blacklist").Return(nil, nil)
+	swept := make(chan struct{}, 1)
+	repository.On("DeleteExpiredNetworks", "whitelist").Return(int64(1), nil)
+	repository.On("DeleteExpiredNetworks", "blacklist").Return(int64(0), nil).Run(func(mock.Arguments) {
+		select {
+		case swept <- struct{}{}:
+		default:
+		}
+	})
+	repository.On("Close").Return(nil)
+
+	service, err := ipfilter.NewServiceWithRepository(logruslogger.NewLogrusLogger("panic"), clock.NewFakeClock(now), repository, time.Millisecond, true, ipfilteriface.ListPrecedence)
+	require.NoError(t, err)
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		t.Fatal("expired entries were not swept")
+	}
+	require.NoError(t, service.Close())
+}
+
+func TestReload(t *testing.T) {
+	service, repository, _ := newService(t, entries("192.168.1.0/24"), nil)
+	repository.On("GetNetworks", "whitelist").Return(entries("10.0.0.0/8"), nil).Once()
+	repository.On("GetNetworks", "blacklist").Return(entries("192.168.1.0/24"), nil).Once()
+
+	require.NoError(t, service.Reload())
+	assert.True(t, service.IsIPWhitelisted("10.0.0.1"))
+	assert.False(t, service.IsIPWhitelisted("192.168.1.1"))
+	assert.True(t, service.IsIPBlacklisted("192.168.1.1"))
+
+	repository.On("GetNetworks", "whitelist").Return(nil, errors.New("connection refused")).Once()
+	assert.Error(t, service.Reload())
+	assert.True(t, service.IsIPWhitelisted("10.0.0.1"))
+}
+
+func TestWatchAppliesChanges(t *testing.T) {
+	service, repository, fakeClock := newService(t, entries("192.168.1.0/24"), nil)
+	repository.On("Close").Return(nil)
+	watcher := iplists.NewFakeWatcher()
+	service.Watch(watcher)
+
+	watcher.Send(iplists.Change{Kind: iplists.NetworkInserted, Table: "blacklist", Network: "10.0.0.0/8"})
+	watcher.Send(iplists.Change{Kind: iplists.NetworkInserted, Table: "blacklist", Network: "172.16.0.0/12", ExpiresAt: now.Add(time.Minute)})
+	watcher.Send(iplists.Change{Kind: iplists.NetworkDeleted, Table: "whitelist", Network: "192.168.1.0/24"})
+	watcher.Send(iplists.Change{Kind: iplists.NetworkInserted, Table: "unknown", Network: "172.17.0.0/16"})
+	watcher.Send(iplists.Change{Kind: iplists.NetworkInserted, Table: "whitelist", Network: "invalid"})
+
+	assert.Eventually(t, func() bool {
+		return service.IsIPBlacklisted("10.0.0.1") && service.IsIPBlacklisted("172.16.0.1") && !service.IsIPWhitelisted("192.168.1.1")
+	}, time.Second, time.Millisecond)
+	assert.False(t, service.IsIPWhitelisted("172.17.0.1"))
+
+	fakeClock.Advance(time.Minute)
+	assert.False(t, service.IsIPBlacklisted("172.16.0.1"))
+
+	require.NoError(t, service.Close())
+	repository.AssertExpectations(t)
+}
+
+func TestWatchReloadsAfterLostChanges(t *testing.T) {
+	service, repository, _ := newService(t, nil, nil)
+	repository.On("Close").Return(nil)
+	watcher := iplists.NewFakeWatcher()
+	service.Watch(watcher)
+
+	// A failed reload keeps the lists as they were
+	repository.On("GetNetworks", "whitelist").Return(nil, errors.New("connection refused")).Once()
+	watcher.Send(iplists.Change{Kind: iplists.ChangesLost})
+
+	repository.On("GetNetworks", "whitelist").Return(entries("192.168.1.0/24"), nil).Once()
+	repository.On("GetNetworks", "blacklist").Return(entries("10.0.0.0/8"), nil).Once()
+	watcher.Send(iplists.Change{Kind: iplists.ChangesLost})
+
+	assert.Eventually(t, func() bool {
+		return service.IsIPWhitelisted("192.168.1.1") && service.IsIPBlacklisted("10.0.0.1")
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, service.Close())
+	repository.AssertExpectations(t)
+}
+
+func listed(list, network string) ipfilteriface.ListedNetwork {
+	return ipfilteriface.ListedNetwork{List: list, Network: network}
+}
+
+func decision(list, network string, action database.Action, priority int) ipfilteriface.Decision {
+	return ipfilteriface.Decision{List: list, Network: network, Rule: database.Rule{Action: action, Priority: priority}}
+}
@@ -0,0 +1,264 @@
+package prometheusmetrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	ipfilteriface "github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/metrics"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics exports the metrics of the service in the Prometheus format.
+// Every label has a bounded set of values: logins, passwords and IPs are
+// never used as labels.
+type Metrics struct {
+	registry      *prometheus.Registry
+	decisions     *prometheus.CounterVec
+	bucketChecks  prometheus.Histogram
+	ipListLookups prometheus.Histogram
+	backendErrors *prometheus.CounterVec
+	grpcRequests  *prometheus.CounterVec
+	grpcDurations *prometheus.HistogramVec
+}
+
+// New creates the metrics on a registry of their own, along with the Go
+// runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ratelimiter_authorize_decisions_total",
+			Help: "Authorize decisions by outcome and reason.",
+		}, []string{"decision", "reason"}),
+		bucketChecks: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ratelimiter_bucket_check_duration_seconds",
+			Help:    "Time taken to check a request against its rate limit buckets.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8),
+		}),
+		ipListLookups: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ratelimiter_ip_list_lookup_duration_seconds",
+			Help:    "Time taken to look an IP up in the whitelist and blacklist.",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 8),
+		}),
+		backendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ratelimiter_backend_errors_total",
+			Help: "Errors returned by the storage backends, by backend and operation.",
+		}, []string{"backend", "operation"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ratelimiter_grpc_requests_total",
+			Help: "gRPC requests handled, by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ratelimiter_grpc_request_duration_seconds",
+			Help:    "Time taken to handle a gRPC request, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+	m.registry.MustRegister(
+		m.decisions,
+		m.bucketChecks,
+		m.ipListLookups,
+		m.backendErrors,
+		m.grpcRequests,
+		m.grpcDurations,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Registry returns the registry the metrics are registered on.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics to Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) AuthorizeDecision(authorized bool, reason metrics.Reason) {
+	decision := "denied"
+	if authorized {
+		decision = "allowed"
+	}
+	m.decisions.WithLabelValues(decision, string(reason)).Inc()
+}
+
+// UnaryServerInterceptor counts the unary calls and measures how long they take.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observeCall(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts the streaming calls and measures how long they take.
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observeCall(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func (m *Metrics) observeCall(method string, start time.Time, err error) {
+	m.grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.grpcDurations.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) backendError(backend, operation string, err error) {
+	if err != nil {
+		m.backendErrors.WithLabelValues(backend, operation).Inc()
+	}
+}
+
+// InstrumentBucketStorage measures the rate limit checks of the storage and
+// counts its errors under the backend name.
+func (m *Metrics) InstrumentBucketStorage(storage bucket.Storage, backend string) bucket.Storage {
+	return &bucketStorage{Storage: storage, metrics: m, backend: backend}
+}
+
+type bucketStorage struct {
+	bucket.Storage
+	metrics *Metrics
+	backend string
+}
+
+func (s *bucketStorage) CheckRateLimit(ctx context.Context, key string, limit bucket.Limit) (bucket.Result, error) {
+	start := time.Now()
+	result, err := s.Storage.CheckRateLimit(ctx, key, limit)
+	s.metrics.bucketChecks.Observe(time.Since(start).Seconds())
+	s.metrics.backendError(s.backend, "check_rate_limit", err)
+	return result, err
+}
+
+func (s *bucketStorage) CheckRateLimits(ctx context.Context, checks []bucket.Check) ([]bucket.Result, error) {
+	start := time.Now()
+	results, err := s.Storage.CheckRateLimits(ctx, checks)
+	s.metrics.bucketChecks.Observe(time.Since(start).Seconds())
+	s.metrics.backendError(s.backend, "check_rate_limits", err)
+	return results, err
+}
+
+func (s *bucketStorage) ResetBucket(ctx context.Context, key string) error {
+	err := s.Storage.ResetBucket(ctx, key)
+	s.metrics.backendError(s.backend, "reset_bucket", err)
+	return err
+}
+
+func (s *bucketStorage) MoveBucket(ctx context.Context, from, to string) error {
+	err := s.Storage.MoveBucket(ctx, from, to)
+	s.metrics.backendError(s.backend, "move_bucket", err)
+	return err
+}
+
+// InstrumentIPFilter measures how long the service takes to evaluate an IP.
+func (m *Metrics) InstrumentIPFilter(service ipfilteriface.Service) ipfilteriface.Service {
+	return &ipFilter{Service: service, metrics: m}
+}
+
+type ipFilter struct {
+	ipfilteriface.Service
+	metrics *Metrics
+}
+
+func (f *ipFilter) Evaluate(ctx context.Context, ip string) (ipfilteriface.Decision, bool, error) {
+	start := time.Now()
+	decision, ok, err := f.Service.Evaluate(ctx, ip)
+	f.metrics.ipListLookups.Observe(time.Since(start).Seconds())
+	return decision, ok, err
+}
+
+// InstrumentRepository counts the errors of the lists repository, which
+// are Postgres errors but for the invalid networks it is given.
+func (m *Metrics) InstrumentRepository(repository iplists.Repository) iplists.Repository {
+	return &repositoryErrors{Repository: repository, metrics: m}
+}
+
+type repositoryErrors struct {
+	iplists.Repository
+	metrics *Metrics
+}
+
+func (r *repositoryErrors) observe(operation string, err error) {
+	r.metrics.backendError("postgres", operation, err)
+}
+
+func (r *repositoryErrors) InsertNetwork(table string, entry database.Entry) error {
+	err := r.Repository.InsertNetwork(table, entry)
+	r.observe("insert_network", err)
+	return err
+}
+
+func (r *repositoryErrors) DeleteNetwork(table, subnet string) (bool, error) {
+	deleted, err := r.Repository.DeleteNetwork(table, subnet)
+	r.observe("delete_network", err)
+	return deleted, err
+}
+
+func (r *repositoryErrors) ImportNetworks(table string, entries []database.Entry, replace bool) (database.ImportResult, error) {
+	result, err := r.Repository.ImportNetworks(table, entries, replace)
+	r.observe("import_networks", err)
+	return result, err
+}
+
+func (r *repositoryErrors) GetNetworks(table string) ([]database.Entry, error) {
+	entries, err := r.Repository.GetNetworks(table)
+	r.observe("get_networks", err)
+	return entries, err
+}
+
+func (r *repositoryErrors) GetSourceNetworks(table, source string) ([]database.Entry, error) {
+	entries, err := r.Repository.GetSourceNetworks(table, source)
+	r.observe("get_source_networks", err)
+	return entries, err
+}
+
+func (r *repositoryErrors) SyncNetworks(table, source string, add []database.Entry, remove []string) (database.ImportResult, error) {
+	result, err := r.Repository.SyncNetworks(table, source, add, remove)
+	r.observe("sync_networks", err)
+	return result, err
+}
+
+func (r *repositoryErrors) ListNetworks(table string, query database.ListQuery) (database.Page, error) {
+	page, err := r.Repository.ListNetworks(table, query)
+	r.observe("list_networks", err)
+	return page, err
+}
+
+func (r *repositoryErrors) GetContainingNetworks(ctx context.Context, table, network string) ([]database.Entry, error) {
+	entries, err := r.Repository.GetContainingNetworks(ctx, table, network)
+	r.observe("get_containing_networks", err)
+	return entries, err
+}
+
+func (r *repositoryErrors) IsNetworkExists(table, subnet string) (bool, error) {
+	exists, err := r.Repository.IsNetworkExists(table, subnet)
+	r.observe("is_network_exists", err)
+	return exists, err
+}
+
+func (r *repositoryErrors) GetNetwork(table, subnet string) (database.Entry, bool, error) {
+	entry, ok, err := r.Repository.GetNetwork(table, subnet)
+	r.observe("get_network", err)
+	return entry, ok, err
+}
+
+func (r *repositoryErrors) DeleteExpiredNetworks(table string) (int64, error) {
+	deleted, err := r.Repository.DeleteExpiredNetworks(table)
+	r.observe("delete_expired_networks", err)
+	return deleted, err
+}
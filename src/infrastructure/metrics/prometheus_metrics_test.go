@@ -0,0 +1,127 @@
+package prometheusmetrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/metrics"
+	"github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/metrics"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizeDecision(t *testing.T) {
+	m := prometheusmetrics.New()
+	m.AuthorizeDecision(true, metrics.Whitelist)
+	m.AuthorizeDecision(false, metrics.LoginLimit)
+	m.AuthorizeDecision(false, metrics.LoginLimit)
+
+	expected := `
+# HELP ratelimiter_authorize_decisions_total Authorize decisions by outcome and reason.
+# TYPE ratelimiter_authorize_decisions_total counter
+ratelimiter_authorize_decisions_total{decision="allowed",reason="whitelist"} 1
+ratelimiter_authorize_decisions_total{decision="denied",reason="login_limit"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "ratelimiter_authorize_decisions_total"))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := prometheusmetrics.New()
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/ratelimiter.RateLimiter/Authorize"}
+
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+	require.Error(t, err)
+
+	expected := `
+# HELP ratelimiter_grpc_requests_total gRPC requests handled, by method and status code.
+# TYPE ratelimiter_grpc_requests_total counter
+ratelimiter_grpc_requests_total{code="OK",method="/ratelimiter.RateLimiter/Authorize"} 1
+ratelimiter_grpc_requests_total{code="Unavailable",method="/ratelimiter.RateLimiter/Authorize"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "ratelimiter_grpc_requests_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.Registry(), "ratelimiter_grpc_request_duration_seconds"))
+}
+
+func TestInstrumentBucketStorage(t *testing.T) {
+	m := prometheusmetrics.New()
+	mockStorage := new(bucket.MockBucketStorage)
+	mockStorage.On("CheckRateLimits", mock.Anything, mock.Anything).Return([]bucket.Result{{Allowed: true}}, nil).Once()
+	mockStorage.On("CheckRateLimits", mock.Anything, mock.Anything).Return([]bucket.Result(nil), errors.New("connection refused")).Once()
+	storage := m.InstrumentBucketStorage(mockStorage, "redis")
+
+	_, err := storage.CheckRateLimits(context.Background(), []bucket.Check{{Key: "key"}})
+	require.NoError(t, err)
+	_, err = storage.CheckRateLimits(context.Background(), []bucket.Check{{Key: "key"}})
+	require.Error(t, err)
+
+	expected := `
+# HELP ratelimiter_backend_errors_total Errors returned by the storage backends, by backend and operation.
+# TYPE ratelimiter_backend_errors_total counter
+ratelimiter_backend_errors_total{backend="redis",operation="check_rate_limits"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "ratelimiter_backend_errors_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.Registry(), "ratelimiter_bucket_check_duration_seconds"))
+	mockStorage.AssertExpectations(t)
+}
+
+func TestInstrumentIPFilter(t *testing.T) {
+	m := prometheusmetrics.New()
+	mockService := new(ipfilter.MockIPFilterService)
+	mockService.On("Evaluate", mock.Anything, "10.0.0.1").Return(ipfilter.Decision{}, false, nil)
+	service := m.InstrumentIPFilter(mockService)
+
+	_, ok, err := service.Evaluate(context.Background(), "10.0.0.1")
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.Registry(), "ratelimiter_ip_list_lookup_duration_seconds"))
+	mockService.AssertExpectations(t)
+}
+
+func TestInstrumentRepository(t *testing.T) {
+	m := prometheusmetrics.New()
+	mockRepository := new(iplists.MockRepository)
+	mockRepository.On("GetContainingNetworks", mock.Anything, "whitelist", "10.0.0.1").Return(nil, errors.New("connection refused"))
+	repository := m.InstrumentRepository(mockRepository)
+
+	_, err := repository.GetContainingNetworks(context.Background(), "whitelist", "10.0.0.1")
+
+	require.Error(t, err)
+	expected := `
+# HELP ratelimiter_backend_errors_total Errors returned by the storage backends, by backend and operation.
+# TYPE ratelimiter_backend_errors_total counter
+ratelimiter_backend_errors_total{backend="postgres",operation="get_containing_networks"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "ratelimiter_backend_errors_total"))
+	mockRepository.AssertExpectations(t)
+}
+
+func TestHandler(t *testing.T) {
+	m := prometheusmetrics.New()
+	m.AuthorizeDecision(false, metrics.Blacklist)
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `ratelimiter_authorize_decisions_total{decision="denied",reason="blacklist"} 1`)
+	assert.Contains(t, recorder.Body.String(), "go_goroutines")
+}
@@ -1,10 +1,14 @@
 package iplistsrepository
 
 import (
+	"context"
+	"fmt"
 	"net"
+	"slices"
 
 	"github.com/TheJubadze/RateLimiter/infrastructure/storage/postgres"
 	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
 )
 
 type Repository struct {
@@ -19,17 +23,21 @@ func NewRepository(connString string) (*Repository, error) {
 	return &Repository{db: db}, nil
 }
 
+func (p *Repository) Ping(ctx context.Context) error {
+	return p.db.Ping(ctx)
+}
+
 func (p *Repository) Close() error {
 	return p.db.Close()
 }
 
-func (p *Repository) InsertNetwork(table, subnet string) error {
-	_, ipNet, err := net.ParseCIDR(subnet)
+func (p *Repository) InsertNetwork(table string, entry database.Entry) error {
+	entry, err := normalizeEntry(table, entry)
 	if err != nil {
 		return err
 	}
 
-	return p.db.Insert(table, ipNet.String())
+	return p.db.Insert(table, entry)
 }
 
 func (p *Repository) DeleteNetwork(table, subnet string) (bool, error) {
@@ -41,10 +49,97 @@ func (p *Repository) DeleteNetwork(table, subnet string) (bool, error) {
 	return p.db.Delete(table, ipNet.String())
 }
 
-func (p *Repository) GetNetworks(table string) ([]string, error) {
+func (p *Repository) ImportNetworks(table string, entries []database.Entry, replace bool) (database.ImportResult, error) {
+	normalized := make([]database.Entry, len(entries))
+	for i, entry := range entries {
+		entry, err := normalizeEntry(table, entry)
+		if err != nil {
+			return database.ImportResult{}, err
+		}
+		normalized[i] = entry
+	}
+
+	return p.db.Import(table, normalized, replace)
+}
+
+func (p *Repository) GetNetworks(table string) ([]database.Entry, error) {
 	return p.db.GetAll(table)
 }
 
+func (p *Repository) GetSourceNetworks(table, source string) ([]database.Entry, error) {
+	return p.db.GetBySource(table, source)
+}
+
+func (p *Repository) SyncNetworks(table, source string, add []database.Entry, remove []string) (database.ImportResult, error) {
+	normalized := make([]database.Entry, len(add))
+	for i, entry := range add {
+		entry, err := normalizeEntry(table, entry)
+		if err != nil {
+			return database.ImportResult{}, err
+		}
+		normalized[i] = entry
+	}
+
+	return p.db.Sync(table, source, normalized, remove)
+}
+
+// ListNetworks returns a page of a list. The networks may be filtered by an
+// IP as well as by a network.
+func (p *Repository) ListNetworks(table string, query database.ListQuery) (database.Page, error) {
+	if query.Contains != "" {
+		contains, err := parseContained(query.Contains)
+		if err != nil {
+			return database.Page{}, err
+		}
+		query.Contains = contains
+	}
+
+	return p.db.List(table, query)
+}
+
+// GetContainingNetworks returns the listed networks holding an IP or a
+// network, shortest prefix first.
+func (p *Repository) GetContainingNetworks(ctx context.Context, table, network string) ([]database.Entry, error) {
+	contained, err := parseContained(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.db.GetContaining(ctx, table, contained)
+}
+
+// normalizeEntry masks the network of an entry and sets its action.
+func normalizeEntry(table string, entry database.Entry) (database.Entry, error) {
+	_, ipNet, err := net.ParseCIDR(entry.Network)
+	if err != nil {
+		return database.Entry{}, err
+	}
+	entry.Network = ipNet.String()
+
+	if entry.Action == "" {
+		entry.Action = iplists.DefaultAction(table)
+	}
+	if !slices.Contains(database.Actions, entry.Action) {
+		return database.Entry{}, fmt.Errorf("unknown action: %q", entry.Action)
+	}
+	return entry, nil
+}
+
+// parseContained parses a network, or an IP as the network holding only it.
+func parseContained(network string) (string, error) {
+	if ip := net.ParseIP(network); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		network = fmt.Sprintf("%s/%d", ip, 8*len(ip))
+	}
+	_, ipNet, err := net.ParseCIDR(network)
+	if err != nil {
+		return "", err
+	}
+	return ipNet.String(), nil
+}
+
 func (p *Repository) IsNetworkExists(table, subnet string) (bool, error) {
 	_, ipNet, err := net.ParseCIDR(subnet)
 	if err != nil {
@@ -53,3 +148,16 @@ func (p *Repository) IsNetworkExists(table, subnet string) (bool, error) {
 
 	return p.db.GetByValue(table, ipNet.String())
 }
+
+func (p *Repository) GetNetwork(table, subnet string) (database.Entry, bool, error) {
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return database.Entry{}, false, err
+	}
+
+	return p.db.GetEntry(table, ipNet.String())
+}
+
+func (p *Repository) DeleteExpiredNetworks(table string) (int64, error) {
+	return p.db.DeleteExpired(table)
+}
@@ -0,0 +1,148 @@
+package iplistsrepository
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/lib/pq"
+)
+
+// changesChannel is notified by the triggers on the list tables.
+const changesChannel = "ip_lists_changes"
+
+const (
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	// pingInterval keeps an idle connection checked, so a dropped one is
+	// noticed and reconnected.
+	pingInterval = 90 * time.Second
+)
+
+// Watcher listens for the notifications sent by the triggers on the list
+// tables. After reconnecting it reports that changes may have been lost.
+type Watcher struct {
+	listener *pq.Listener
+	changes  chan iplists.Change
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+type notification struct {
+	Table     string     `json:"table"`
+	Op        string     `json:"op"`
+	Network   string     `json:"network"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Action    string     `json:"action"`
+	Priority  int        `json:"priority"`
+}
+
+func NewWatcher(logger logger.Logger, connString string) (*Watcher, error) {
+	listener := pq.NewListener(connString, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			logger.Printf("IP lists watcher disconnected: %v", err)
+		case pq.ListenerEventReconnected:
+			logger.Printf("IP lists watcher reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			logger.Printf("IP lists watcher failed to reconnect: %v", err)
+		}
+	})
+	if err := listener.Listen(changesChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		listener: listener,
+		changes:  make(chan iplists.Change),
+		done:     make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+func (w *Watcher) Changes() <-chan iplists.Change {
+	return w.changes
+}
+
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.listener.Close()
+		w.wg.Wait()
+	})
+	return err
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+	defer close(w.changes)
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			go func() {
+				_ = w.listener.Ping()
+			}()
+		case n, ok := <-w.listener.Notify:
+			if !ok {
+				return
+			}
+			select {
+			case w.changes <- toChange(n):
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// toChange decodes a notification. pq sends a nil notification after
+// reconnecting, and anything that can't be decoded is treated the same way.
+func toChange(n *pq.Notification) iplists.Change {
+	lost := iplists.Change{Kind: iplists.ChangesLost}
+	if n == nil {
+		return lost
+	}
+
+	var payload notification
+	if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+		return lost
+	}
+
+	switch payload.Op {
+	case "INSERT", "UPDATE":
+		// An updated network replaces the one held, like an inserted one
+		change := iplists.Change{
+			Kind:    iplists.NetworkInserted,
+			Table:   payload.Table,
+			Network: payload.Network,
+			Rule:    database.Rule{Action: database.Action(payload.Action), Priority: payload.Priority},
+		}
+		if payload.ExpiresAt != nil {
+			change.ExpiresAt = *payload.ExpiresAt
+		}
+		// Triggers from before rules were added send no action
+		if change.Rule.Action == "" {
+			change.Rule.Action = iplists.DefaultAction(payload.Table)
+		}
+		return change
+	case "DELETE":
+		return iplists.Change{Kind: iplists.NetworkDeleted, Table: payload.Table, Network: payload.Network}
+	default:
+		return lost
+	}
+}
@@ -0,0 +1,218 @@
+package memorystorage
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+)
+
+// state holds the state of one bucket. Each algorithm uses its own fields,
+// and all times are Unix microseconds.
+type state struct {
+	level     float64 // leaky bucket level or token bucket tokens
+	last      int64   // last leak or refill
+	start     int64   // current window start
+	current   float64 // requests in the current window
+	previous  float64 // requests in the previous window
+	log       []int64 // request times in the last period, oldest first
+	tat       int64   // GCRA theoretical arrival time
+	expiresAt int64   // time after which the bucket is back to its initial state
+}
+
+// algorithm checks whether a request fits into a bucket at time now.
+// It returns the bucket state with the request recorded if it does, and
+// what is left of the bucket afterwards.
+// Times are Unix microseconds, the period is in microseconds.
+// These mirror the Lua implementations of the Redis storage.
+type algorithm func(st state, now int64, capacity, period float64) (state, bucket.Result)
+
+var algorithms = map[bucket.Algorithm]algorithm{
+	bucket.LeakyBucket:          leakyBucket,
+	bucket.TokenBucket:          tokenBucket,
+	bucket.FixedWindow:          fixedWindow,
+	bucket.SlidingWindowLog:     slidingWindowLog,
+	bucket.SlidingWindowCounter: slidingWindowCounter,
+	bucket.GCRA:                 gcra,
+}
+
+// newResult rounds the remaining requests down and the wait in microseconds up.
+func newResult(allowed bool, remaining, retryAfter float64) bucket.Result {
+	if remaining >= 1 {
+		retryAfter = 0
+	}
+	return bucket.Result{
+		Allowed:    allowed,
+		Remaining:  int(math.Max(0, math.Floor(remaining))),
+		RetryAfter: time.Duration(math.Max(0, math.Ceil(retryAfter))) * time.Microsecond,
+	}
+}
+
+// leakyBucket leaks capacity requests per period. The level is kept as
+// a fraction, so partially leaked requests carry over to the next check.
+func leakyBucket(st state, now int64, capacity, period float64) (state, bucket.Result) {
+	if st.last == 0 {
+		st.last = now
+	}
+
+	level := math.Max(0, st.level-math.Max(0, float64(now-st.last))/period*capacity)
+	allowed := level+1 <= capacity
+	if allowed {
+		level++
+		st.level = level
+		st.last = now
+		st.expiresAt = now + int64(math.Ceil(st.level*period/capacity))
+	}
+
+	remaining := capacity - level
+	return st, newResult(allowed, remaining, (1-remaining)/capacity*period)
+}
+
+// tokenBucket starts full and refills capacity tokens per period.
+func tokenBucket(st state, now int64, capacity, period float64) (state, bucket.Result) {
+	tokens := capacity
+	if st.last != 0 {
+		tokens = math.Min(capacity, st.level+math.Max(0, float64(now-st.last))/period*capacity)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+		st.level = tokens
+		st.last = now
+		st.expiresAt = now + int64(math.Ceil((capacity-st.level)*period/capacity))
+	}
+
+	return st, newResult(allowed, tokens, (1-tokens)/capacity*period)
+}
+
+// fixedWindow counts requests in consecutive windows of one period.
+func fixedWindow(st state, now int64, capacity, period float64) (state, bucket.Result) {
+	start := now - now%int64(period)
+	count := 0.0
+	if st.start == start {
+		count = st.current
+	}
+
+	allowed := count+1 <= capacity
+	if allowed {
+		count++
+		st.start = start
+		st.current = count
+		st.expiresAt = start + int64(period)
+	}
+
+	return st, newResult(allowed, capacity-count, float64(start+int64(period)-now))
+}
+
+// slidingWindowLog keeps the time of every request in the last period.
+func slidingWindowLog(st state, now int64, capacity, period float64) (state, bucket.Result) {
+	cutoff := now - int64(period)
+	i := 0
+	for i < len(st.log) && st.log[i] <= cutoff {
+		i++
+	}
+	log := st.log[i:]
+
+	allowed := float64(len(log))+1 <= capacity
+	if allowed {
+		// Copy, so the stored log is never shared with a previous state
+		log = append(log[:len(log):len(log)], now)
+		st.log = log
+		st.expiresAt = now + int64(period)
+	}
+
+	oldest := now
+	if len(log) > 0 {
+		oldest = log[0]
+	}
+	return st, newResult(allowed, capacity-float64(len(log)), float64(oldest+int64(period)-now))
+}
+
+// slidingWindowCounter estimates the requests in the last period from the
+// counts of the current and the previous fixed window, weighting the
+// previous one by its overlap.
+func slidingWindowCounter(st state, now int64, capacity, period float64) (state, bucket.Result) {
+	start := now - now%int64(period)
+	current, previous := st.current, st.previous
+	if st.start != start {
+		if st.start == start-int64(period) {
+			previous = current
+		} else {
+			previous = 0
+		}
+		current = 0
+	}
+
+	weight := 1 - float64(now-start)/period
+	allowed := previous*weight+current+1 <= capacity
+	if allowed {
+		current++
+		st.start = start
+		st.current = current
+		st.previous = previous
+		st.expiresAt = start + 2*int64(period)
+	}
+
+	// The next request fits once the previous window has slid far enough
+	// out, or if the current window alone is full, into the next window.
+	var retryAt float64
+	if room := capacity - 1 - current; room >= 0 {
+		retryAt = float64(start) + period*(1-room/previous)
+	} else {
+		retryAt = float64(start) + period + period*(1-(capacity-1)/current)
+	}
+
+	return st, newResult(allowed, capacity-(previous*weight+current), retryAt-float64(now))
+}
+
+// gcra is the generic cell rate algorithm: requests are spaced
+// period/capacity apart, with a burst of up to capacity requests.
+func gcra(st state, now int64, capacity, period float64) (state, bucket.Result) {
+	interval := int64(math.Max(1, math.Floor(period/capacity)))
+	tat := st.tat
+	if tat < now {
+		tat = now
+	}
+
+	allowed := float64(tat+interval-now) <= period
+	if allowed {
+		tat += interval
+		st.tat = tat
+		st.expiresAt = tat
+	}
+
+	remaining := math.Floor((period - float64(tat-now)) / float64(interval))
+	return st, newResult(allowed, remaining, float64(tat+interval-now)-period)
+}
+
+// merge merges the states of two buckets of the same algorithm, keeping
+// the one with the most requests recorded, so moving a bucket onto another
+// never gives requests back. These mirror the merges of the Redis storage.
+func merge(algorithm bucket.Algorithm, a, b state) state {
+	merged := a
+	merged.expiresAt = max(a.expiresAt, b.expiresAt)
+	switch algorithm {
+	case bucket.LeakyBucket:
+		merged.level = math.Max(a.level, b.level)
+		merged.last = max(a.last, b.last)
+	case bucket.TokenBucket:
+		merged.level = math.Min(a.level, b.level)
+		merged.last = max(a.last, b.last)
+	case bucket.FixedWindow, bucket.SlidingWindowCounter:
+		// The later window wins, the counts of the same window are merged
+		if b.start > a.start {
+			merged.start, merged.current, merged.previous = b.start, b.current, b.previous
+		} else if b.start == a.start {
+			merged.current = math.Max(a.current, b.current)
+			merged.previous = math.Max(a.previous, b.previous)
+		}
+	case bucket.SlidingWindowLog:
+		merged.log = append(slices.Clip(a.log), b.log...)
+		slices.Sort(merged.log)
+	case bucket.GCRA:
+		merged.tat = max(a.tat, b.tat)
+	}
+	return merged
+}
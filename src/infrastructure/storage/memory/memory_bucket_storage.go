@@ -0,0 +1,224 @@
+package memorystorage
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+)
+
+const shardCount = 64
+
+type bucketID struct {
+	key       string
+	algorithm bucket.Algorithm
+}
+
+type shard struct {
+	index   int
+	mu      sync.Mutex
+	buckets map[bucketID]state
+}
+
+// MemoryBucketStorage keeps buckets in process memory, for single node
+// deployments and tests. Buckets are spread over shards with their own
+// locks, and buckets that have fully drained are evicted in the background.
+type MemoryBucketStorage struct {
+	logger logger.Logger
+	clock  clock.Clock
+	shards [shardCount]*shard
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewMemoryBucketStorage creates the storage and, if evictionInterval is
+// positive, starts evicting idle buckets at that interval until Close is called.
+func NewMemoryBucketStorage(logger logger.Logger, clock clock.Clock, evictionInterval time.Duration) *MemoryBucketStorage {
+	m := &MemoryBucketStorage{
+		logger: logger,
+		clock:  clock,
+		done:   make(chan struct{}),
+	}
+	for i := range m.shards {
+		m.shards[i] = &shard{index: i, buckets: make(map[bucketID]state)}
+	}
+
+	if evictionInterval > 0 {
+		m.wg.Add(1)
+		go m.evictLoop(evictionInterval)
+	}
+
+	return m
+}
+
+func (m *MemoryBucketStorage) CheckRateLimit(ctx context.Context, key string, limit bucket.Limit) (bucket.Result, error) {
+	results, err := m.CheckRateLimits(ctx, []bucket.Check{{Key: key, Limit: limit}})
+	if err != nil {
+		return bucket.Result{}, err
+	}
+	return results[0], nil
+}
+
+func (m *MemoryBucketStorage) CheckRateLimits(_ context.Context, checks []bucket.Check) ([]bucket.Result, error) {
+	shards := make([]*shard, len(checks))
+	for i, check := range checks {
+		if err := check.Limit.Validate(); err != nil {
+			return nil, err
+		}
+		shards[i] = m.shard(check.Key)
+	}
+
+	unlock := lockShards(shards...)
+	defer unlock()
+
+	now := m.clock.Now().UnixMicro()
+	states := make([]state, len(checks))
+	results := make([]bucket.Result, len(checks))
+	admitted := true
+	for i, check := range checks {
+		limit := check.Limit
+		st, ok := shards[i].buckets[bucketID{key: check.Key, algorithm: limit.Algorithm}]
+		if ok && st.expiresAt <= now {
+			st = state{}
+		}
+
+		states[i], results[i] = algorithms[limit.Algorithm](st, now, float64(limit.Capacity), float64(limit.Period.Microseconds()))
+		admitted = admitted && results[i].Allowed
+	}
+	bucket.LogResults(m.logger, checks, results)
+
+	if admitted {
+		for i, check := range checks {
+			shards[i].buckets[bucketID{key: check.Key, algorithm: check.Limit.Algorithm}] = states[i]
+		}
+	}
+
+	return results, nil
+}
+
+func (m *MemoryBucketStorage) ResetBucket(_ context.Context, key string) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, algorithm := range bucket.Algorithms {
+		delete(s.buckets, bucketID{key: key, algorithm: algorithm})
+	}
+	return nil
+}
+
+// MoveBucket carries the bucket stored under from over to the key to,
+// merging it with the bucket to already has, if any. The bucket under from
+// is removed.
+func (m *MemoryBucketStorage) MoveBucket(_ context.Context, from, to string) error {
+	src, dst := m.shard(from), m.shard(to)
+	unlock := lockShards(src, dst)
+	defer unlock()
+
+	now := m.clock.Now().UnixMicro()
+	for _, algorithm := range bucket.Algorithms {
+		srcID := bucketID{key: from, algorithm: algorithm}
+		st, ok := src.buckets[srcID]
+		if !ok {
+			continue
+		}
+		delete(src.buckets, srcID)
+		if st.expiresAt <= now {
+			continue
+		}
+		dstID := bucketID{key: to, algorithm: algorithm}
+		if existing, exists := dst.buckets[dstID]; exists && existing.expiresAt > now {
+			st = merge(algorithm, existing, st)
+		}
+		dst.buckets[dstID] = st
+	}
+	return nil
+}
+
+// Len returns the number of buckets held.
+func (m *MemoryBucketStorage) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		n += len(s.buckets)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Close stops the background eviction.
+func (m *MemoryBucketStorage) Close() error {
+	close(m.done)
+	m.wg.Wait()
+	return nil
+}
+
+func (m *MemoryBucketStorage) evictLoop(interval time.Duration) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.evict()
+		}
+	}
+}
+
+// evict removes the buckets that are back to their initial state.
+func (m *MemoryBucketStorage) evict() {
+	evicted := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		now := m.clock.Now().UnixMicro()
+		for id, st := range s.buckets {
+			if st.expiresAt <= now {
+				delete(s.buckets, id)
+				evicted++
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	if evicted > 0 {
+		m.logger.Printf("Evicted %d idle buckets", evicted)
+	}
+}
+
+func (m *MemoryBucketStorage) shard(key string) *shard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return m.shards[h.Sum32()%shardCount]
+}
+
+// lockShards locks the distinct shards among the given ones in index order,
+// so concurrent callers can't deadlock, and returns a function unlocking them.
+func lockShards(shards ...*shard) func() {
+	sorted := make([]*shard, 0, len(shards))
+	for _, s := range shards {
+		if !slices.Contains(sorted, s) {
+			sorted = append(sorted, s)
+		}
+	}
+	slices.SortFunc(sorted, func(a, b *shard) int {
+		return a.index - b.index
+	})
+
+	for _, s := range sorted {
+		s.mu.Lock()
+	}
+	return func() {
+		for i := len(sorted) - 1; i >= 0; i-- {
+			sorted[i].mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package memorystorage_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/infrastructure/storage/memory"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket/buckettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	buckettest.RunConformanceTests(t, func(t *testing.T, clock clock.Clock) bucket.Storage {
+		t.Helper()
+		storage := memorystorage.NewMemoryBucketStorage(logruslogger.NewLogrusLogger("panic"), clock, 0)
+		t.Cleanup(func() { _ = storage.Close() })
+		return storage
+	})
+}
+
+// recordingLogger keeps the lines logged.
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Fatalf(format string, v ...interface{}) {
+	l.Printf(format, v...)
+}
+
+func (l *recordingLogger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+func TestCheckRateLimitsLogsOnlyRejectingBucketsOfRejectedBatch(t *testing.T) {
+	logger := &recordingLogger{}
+	storage := memorystorage.NewMemoryBucketStorage(logger, clock.NewFakeClock(time.Unix(1700000000, 0)), 0)
+	defer storage.Close()
+
+	checks := []bucket.Check{
+		{Key: "large", Limit: bucket.Limit{Algorithm: bucket.LeakyBucket, Capacity: 5, Period: time.Hour}},
+		{Key: "small", Limit: bucket.Limit{Algorithm: bucket.LeakyBucket, Capacity: 1, Period: time.Hour}},
+	}
+	_, err := storage.CheckRateLimits(context.Background(), checks)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Key: large, algorithm: leaky_bucket - allowed, 4 remaining",
+		"Key: small, algorithm: leaky_bucket - allowed, 0 remaining",
+	}, logger.Lines())
+
+	_, err = storage.CheckRateLimits(context.Background(), checks)
+	require.NoError(t, err)
+	assert.Equal(t, "Key: small, algorithm: leaky_bucket - rate limit exceeded, retry after 1h0m0s", logger.Lines()[2])
+	assert.Len(t, logger.Lines(), 3)
+}
+
+func TestEvictsIdleBuckets(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1700000000, 0))
+	storage := memorystorage.NewMemoryBucketStorage(logruslogger.NewLogrusLogger("panic"), fakeClock, time.Millisecond)
+	defer storage.Close()
+
+	limit := bucket.Limit{Algorithm: bucket.LeakyBucket, Capacity: 2, Period: time.Minute}
+	for _, key := range []string{"first", "second", "third"} {
+		result, err := storage.CheckRateLimit(context.Background(), key, limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	assert.Equal(t, 3, storage.Len())
+
+	// One request drains from a bucket of two in half a period
+	fakeClock.Advance(30 * time.Second)
+	assert.Eventually(t, func() bool { return storage.Len() == 0 }, time.Second, time.Millisecond)
+}
@@ -1,20 +1,39 @@
 package postgresdb
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
-	// postgres driver.
-	_ "github.com/lib/pq"
+	"github.com/TheJubadze/RateLimiter/infrastructure/tracing"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/lib/pq"
 )
 
+// entryColumns are the columns scanEntry reads.
+const entryColumns = "network, created_at, expires_at, reason, created_by, ticket, labels, source, action, priority"
+
+// notExpired is the condition selecting the entries that still apply.
+const notExpired = "(expires_at IS NULL OR expires_at > now())"
+
 type Database struct {
 	DB *sql.DB
 }
 
 func NewDatabase(connStr string) (*Database, error) {
-	db, err := sql.Open("postgres", connStr)
+	// Queries made with the context of a traced call get spans of their own
+	db, err := otelsql.Open("postgres", connStr,
+		otelsql.WithTracerProvider(oteltracing.CallTracerProvider()),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -26,20 +45,189 @@ func NewDatabase(connStr string) (*Database, error) {
 	return &Database{DB: db}, nil
 }
 
-func (d *Database) Insert(table string, network string) error {
+// Insert adds an entry. An expired entry for the same network that was
+// not swept yet is replaced, in the same transaction.
+func (d *Database) Insert(table string, entry database.Entry) error {
 	sanitizedTable, err := sanitizeTableName(table)
 	if err != nil {
 		return err
 	}
 
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf("DELETE FROM %s WHERE network = $1 AND expires_at <= now()", sanitizedTable)
+	if _, err := tx.Exec(query, entry.Network); err != nil {
+		return fmt.Errorf("failed to delete expired network: %w", err)
+	}
+
+	labels, err := encodeLabels(entry.Labels)
+	if err != nil {
+		return err
+	}
+
 	// #nosec G201 - sanitized table name is safe
-	query := fmt.Sprintf("INSERT INTO %s (network) VALUES ($1)", sanitizedTable)
-	_, err = d.DB.Exec(query, network)
+	query = fmt.Sprintf(`INSERT INTO %s (network, expires_at, reason, created_by, ticket, labels, source, action, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, sanitizedTable)
+	_, err = tx.Exec(query, entry.Network, nullTime(entry.ExpiresAt),
+		entry.Reason, entry.CreatedBy, entry.Ticket, labels, entry.Source, entry.Action, entry.Priority)
 	if err != nil {
 		return fmt.Errorf("failed to insert network: %w", err)
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+func (d *Database) Import(table string, entries []database.Entry, replace bool) (database.ImportResult, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return database.ImportResult{}, err
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return database.ImportResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var result database.ImportResult
+	if replace {
+		networks := make([]string, len(entries))
+		for i, entry := range entries {
+			networks[i] = entry.Network
+		}
+		// #nosec G201 - sanitized table name is safe
+		query := fmt.Sprintf("DELETE FROM %s WHERE source = '' AND network <> ALL($1)", sanitizedTable)
+		res, err := tx.Exec(query, pq.Array(networks))
+		if err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to delete networks: %w", err)
+		}
+		if result.Removed, err = res.RowsAffected(); err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+	}
+
+	// An entry that had expired is listed anew, any other keeps its creation time.
+	// A network a feed listed is left to the feed, unless its entry expired
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf(`INSERT INTO %[1]s (network, expires_at, reason, created_by, ticket, labels, source, action, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (network) DO UPDATE SET
+			created_at = CASE WHEN %[1]s.expires_at <= now() THEN now() ELSE %[1]s.created_at END,
+			expires_at = EXCLUDED.expires_at,
+			reason = EXCLUDED.reason,
+			created_by = EXCLUDED.created_by,
+			ticket = EXCLUDED.ticket,
+			labels = EXCLUDED.labels,
+			source = EXCLUDED.source,
+			action = EXCLUDED.action,
+			priority = EXCLUDED.priority
+		WHERE %[1]s.source = '' OR %[1]s.expires_at <= now()`, sanitizedTable)
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return database.ImportResult{}, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		labels, err := encodeLabels(entry.Labels)
+		if err != nil {
+			return database.ImportResult{}, err
+		}
+		res, err := stmt.Exec(entry.Network, nullTime(entry.ExpiresAt), entry.Reason, entry.CreatedBy, entry.Ticket, labels,
+			entry.Source, entry.Action, entry.Priority)
+		if err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to insert network %s: %w", entry.Network, err)
+		}
+		imported, err := res.RowsAffected()
+		if err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		result.Imported += imported
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.ImportResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+func (d *Database) Sync(table string, source string, add []database.Entry, remove []string) (database.ImportResult, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return database.ImportResult{}, err
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return database.ImportResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var result database.ImportResult
+	if len(remove) > 0 {
+		// #nosec G201 - sanitized table name is safe
+		query := fmt.Sprintf("DELETE FROM %s WHERE source = $1 AND network = ANY($2)", sanitizedTable)
+		res, err := tx.Exec(query, source, pq.Array(remove))
+		if err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to delete networks: %w", err)
+		}
+		if result.Removed, err = res.RowsAffected(); err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+	}
+
+	// A network listed by hand is left alone, unless its entry expired
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf(`INSERT INTO %[1]s (network, expires_at, reason, created_by, ticket, labels, source, action, priority)
+		VALUES ($1, NULL, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (network) DO UPDATE SET
+			created_at = now(),
+			expires_at = NULL,
+			reason = EXCLUDED.reason,
+			created_by = EXCLUDED.created_by,
+			ticket = EXCLUDED.ticket,
+			labels = EXCLUDED.labels,
+			source = EXCLUDED.source,
+			action = EXCLUDED.action,
+			priority = EXCLUDED.priority
+		WHERE %[1]s.expires_at <= now()`, sanitizedTable)
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return database.ImportResult{}, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range add {
+		labels, err := encodeLabels(entry.Labels)
+		if err != nil {
+			return database.ImportResult{}, err
+		}
+		res, err := stmt.Exec(entry.Network, entry.Reason, entry.CreatedBy, entry.Ticket, labels, source, entry.Action, entry.Priority)
+		if err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to insert network %s: %w", entry.Network, err)
+		}
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return database.ImportResult{}, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		result.Imported += inserted
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.ImportResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
 }
 
 func (d *Database) Delete(table string, network string) (bool, error) {
@@ -63,34 +251,96 @@ func (d *Database) Delete(table string, network string) (bool, error) {
 	return rowsAffected > 0, nil
 }
 
-func (d *Database) GetAll(table string) ([]string, error) {
+func (d *Database) GetAll(table string) ([]database.Entry, error) {
 	sanitizedTable, err := sanitizeTableName(table)
 	if err != nil {
 		return nil, err
 	}
 
 	// #nosec G201 - sanitized table name is safe
-	query := fmt.Sprintf("SELECT network FROM %s", sanitizedTable)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", entryColumns, sanitizedTable, notExpired)
 	rows, err := d.DB.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select networks: %w", err)
 	}
 	defer rows.Close()
 
-	var networks []string
+	var entries []database.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (d *Database) GetBySource(table string, source string) ([]database.Entry, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE source = $1", entryColumns, sanitizedTable)
+	rows, err := d.DB.Query(query, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select networks: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []database.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetContaining runs on the GiST index of the network column.
+func (d *Database) GetContaining(ctx context.Context, table string, network string) ([]database.Entry, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE network >>= $1 AND %s ORDER BY masklen(network)",
+		entryColumns, sanitizedTable, notExpired)
+	rows, err := d.DB.QueryContext(ctx, query, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select networks: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []database.Entry
 	for rows.Next() {
-		var network string
-		if err := rows.Scan(&network); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
 		}
-		networks = append(networks, network)
+		entries = append(entries, entry)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return networks, nil
+	return entries, nil
 }
 
 func (d *Database) GetByValue(table string, network string) (bool, error) {
@@ -100,7 +350,7 @@ func (d *Database) GetByValue(table string, network string) (bool, error) {
 	}
 
 	// #nosec G201 - sanitized table name is safe
-	query := fmt.Sprintf("SELECT network FROM %s WHERE network = $1", sanitizedTable)
+	query := fmt.Sprintf("SELECT network FROM %s WHERE network = $1 AND %s", sanitizedTable, notExpired)
 	rows, err := d.DB.Query(query, network)
 	if err != nil {
 		return false, err
@@ -117,10 +367,139 @@ func (d *Database) GetByValue(table string, network string) (bool, error) {
 	return false, nil
 }
 
+func (d *Database) List(table string, query database.ListQuery) (database.Page, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return database.Page{}, err
+	}
+	if query.Limit <= 0 {
+		return database.Page{}, fmt.Errorf("invalid page size: %d", query.Limit)
+	}
+
+	conditions := []string{notExpired}
+	var args []interface{}
+	if query.Contains != "" {
+		args = append(args, query.Contains)
+		conditions = append(conditions, fmt.Sprintf("network >>= $%d", len(args)))
+	}
+	order, after := "ASC", ">"
+	if query.NewestFirst {
+		order, after = "DESC", "<"
+	}
+	if query.After != nil {
+		args = append(args, query.After.CreatedAt, query.After.Network)
+		conditions = append(conditions, fmt.Sprintf("(created_at, network) %s ($%d, $%d)", after, len(args)-1, len(args)))
+	}
+	// One more than asked for tells whether there is a next page
+	args = append(args, query.Limit+1)
+
+	// #nosec G201 - sanitized table name and fixed conditions are safe
+	statement := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY created_at %s, network %s LIMIT $%d",
+		entryColumns, sanitizedTable, strings.Join(conditions, " AND "), order, order, len(args))
+	rows, err := d.DB.Query(statement, args...)
+	if err != nil {
+		return database.Page{}, fmt.Errorf("failed to select networks: %w", err)
+	}
+	defer rows.Close()
+
+	var page database.Page
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return database.Page{}, err
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return database.Page{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(page.Entries) > query.Limit {
+		page.Entries = page.Entries[:query.Limit]
+		last := page.Entries[query.Limit-1]
+		page.Next = &database.Cursor{CreatedAt: last.CreatedAt, Network: last.Network}
+	}
+	return page, nil
+}
+
+func (d *Database) GetEntry(table string, network string) (database.Entry, bool, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return database.Entry{}, false, err
+	}
+
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE network = $1 AND %s", entryColumns, sanitizedTable, notExpired)
+	entry, err := scanEntry(d.DB.QueryRow(query, network))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Entry{}, false, nil
+	}
+	if err != nil {
+		return database.Entry{}, false, err
+	}
+
+	return entry, true, nil
+}
+
+func (d *Database) DeleteExpired(table string) (int64, error) {
+	sanitizedTable, err := sanitizeTableName(table)
+	if err != nil {
+		return 0, err
+	}
+
+	// #nosec G201 - sanitized table name is safe
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= now()", sanitizedTable)
+	result, err := d.DB.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired networks: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (d *Database) Ping(ctx context.Context) error {
+	return d.DB.PingContext(ctx)
+}
+
 func (d *Database) Close() error {
 	return d.DB.Close()
 }
 
+// scanEntry scans a row of entryColumns.
+func scanEntry(row interface{ Scan(dest ...any) error }) (database.Entry, error) {
+	var entry database.Entry
+	var createdAt, expiresAt sql.NullTime
+	var labels []byte
+	err := row.Scan(&entry.Network, &createdAt, &expiresAt,
+		&entry.Reason, &entry.CreatedBy, &entry.Ticket, &labels, &entry.Source, &entry.Action, &entry.Priority)
+	if err != nil {
+		return database.Entry{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	entry.CreatedAt = createdAt.Time
+	entry.ExpiresAt = expiresAt.Time
+	if err := json.Unmarshal(labels, &entry.Labels); err != nil {
+		return database.Entry{}, fmt.Errorf("failed to decode labels: %w", err)
+	}
+	return entry, nil
+}
+
+// encodeLabels encodes labels for the jsonb labels column.
+func encodeLabels(labels map[string]string) (string, error) {
+	if labels == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(labels)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode labels: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
 func sanitizeTableName(table string) (string, error) {
 	if matched, _ := regexp.MatchString("^[a-zA-Z0-9_]+$", table); !matched {
 		return "", fmt.Errorf("invalid table name: %s", table)
@@ -0,0 +1,262 @@
+package redisstorage
+
+import (
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/go-redis/redis/v8"
+)
+
+// algorithmSuffixes lists the keys, relative to the bucket key, that hold
+// the state of a bucket for every algorithm.
+var algorithmSuffixes = map[bucket.Algorithm][]string{
+	bucket.LeakyBucket:          {":count", ":lastLeak"},
+	bucket.TokenBucket:          {":tokens", ":lastRefill"},
+	bucket.FixedWindow:          {":fixedWindow"},
+	bucket.SlidingWindowLog:     {":log"},
+	bucket.SlidingWindowCounter: {":slidingWindow"},
+	bucket.GCRA:                 {":tat"},
+}
+
+// suffixMerges tells how moveBucketScript merges every state key with the
+// same key of another bucket, keeping the one with the most requests recorded:
+// max or min of the values, the later window of a hash with a start field,
+// or the union of a log.
+var suffixMerges = map[string]string{
+	":count":         "max",
+	":lastLeak":      "max",
+	":tokens":        "min",
+	":lastRefill":    "max",
+	":fixedWindow":   "window",
+	":log":           "log",
+	":slidingWindow": "window",
+	":tat":           "max",
+}
+
+// allSuffixes returns the state keys of every algorithm, relative to the bucket key.
+func allSuffixes() []string {
+	var suffixes []string
+	for _, algorithm := range bucket.Algorithms {
+		suffixes = append(suffixes, algorithmSuffixes[algorithm]...)
+	}
+	return suffixes
+}
+
+// algorithmsLua implements every algorithm as a Lua function taking the
+// state keys of a bucket, the current Unix time, the capacity and the period,
+// with times in microseconds. Each function returns whether a request fits,
+// how many requests fit after it and how long until the next one does, and
+// if it fits, a function that records it.
+// Timestamps are formatted explicitly, since Lua would print them in
+// exponent notation and lose precision.
+const algorithmsLua = `
+local function int(x)
+  return string.format("%.0f", x)
+end
+
+local function ttl(microseconds)
+  return math.max(1, math.ceil(microseconds / 1000))
+end
+
+-- Rounds the remaining requests down and the wait up, there is no wait
+-- while a request still fits.
+local function result(remaining, retryAfter)
+  if remaining >= 1 then
+    retryAfter = 0
+  end
+  return math.max(0, math.floor(remaining)), math.max(0, math.ceil(retryAfter))
+end
+
+local algorithms = {}
+
+-- A bucket that leaks capacity requests per period. The level is kept as
+-- a fraction, so partially leaked requests carry over to the next check.
+algorithms.leaky_bucket = function(keys, now, capacity, period)
+  local level = tonumber(redis.call("GET", keys[1]) or "0") or 0
+  local lastLeak = tonumber(redis.call("GET", keys[2]) or "0") or 0
+
+  if lastLeak == 0 then
+    lastLeak = now
+  elseif lastLeak < 1e12 then
+    -- written before microsecond precision, in seconds
+    lastLeak = lastLeak * 1e6
+  end
+
+  level = math.max(0, level - math.max(0, now - lastLeak) / period * capacity)
+  local allowed = level + 1 <= capacity
+  if allowed then
+    level = level + 1
+  end
+
+  local remaining = capacity - level
+  local left, wait = result(remaining, (1 - remaining) / capacity * period)
+  return allowed, left, wait, function()
+    local expire = ttl(level * period / capacity)
+    redis.call("SET", keys[1], tostring(level), "PX", expire)
+    redis.call("SET", keys[2], int(now), "PX", expire)
+  end
+end
+
+-- A bucket that starts full and refills capacity tokens per period.
+algorithms.token_bucket = function(keys, now, capacity, period)
+  local tokens = tonumber(redis.call("GET", keys[1]) or "")
+  local lastRefill = tonumber(redis.call("GET", keys[2]) or "")
+
+  if tokens == nil or lastRefill == nil then
+    tokens = capacity
+    lastRefill = now
+  end
+
+  tokens = math.min(capacity, tokens + math.max(0, now - lastRefill) / period * capacity)
+  local allowed = tokens >= 1
+  if allowed then
+    tokens = tokens - 1
+  end
+
+  local left, wait = result(tokens, (1 - tokens) / capacity * period)
+  return allowed, left, wait, function()
+    local expire = ttl((capacity - tokens) * period / capacity)
+    redis.call("SET", keys[1], tostring(tokens), "PX", expire)
+    redis.call("SET", keys[2], int(now), "PX", expire)
+  end
+end
+
+-- Counts requests in consecutive windows of one period.
+algorithms.fixed_window = function(keys, now, capacity, period)
+  local start = now - now % period
+  local count = 0
+  if tonumber(redis.call("HGET", keys[1], "start")) == start then
+    count = tonumber(redis.call("HGET", keys[1], "count")) or 0
+  end
+
+  local allowed = count + 1 <= capacity
+  if allowed then
+    count = count + 1
+  end
+
+  local left, wait = result(capacity - count, start + period - now)
+  return allowed, left, wait, function()
+    redis.call("HSET", keys[1], "start", int(start), "count", count)
+    redis.call("PEXPIRE", keys[1], ttl(start + period - now))
+  end
+end
+
+-- Keeps the time of every request in the last period.
+algorithms.sliding_window_log = function(keys, now, capacity, period)
+  redis.call("ZREMRANGEBYSCORE", keys[1], "-inf", int(now - period))
+  local count = redis.call("ZCARD", keys[1])
+  local oldest = tonumber(redis.call("ZRANGE", keys[1], 0, 0, "WITHSCORES")[2]) or now
+
+  local allowed = count + 1 <= capacity
+  local logged = count
+  if allowed then
+    logged = count + 1
+  end
+
+  local left, wait = result(capacity - logged, oldest + period - now)
+  return allowed, left, wait, function()
+    redis.call("ZADD", keys[1], int(now), int(now) .. ":" .. count)
+    redis.call("PEXPIRE", keys[1], ttl(period))
+  end
+end
+
+-- Estimates the requests in the last period from the counts of the current
+-- and the previous fixed window, weighting the previous one by its overlap.
+algorithms.sliding_window_counter = function(keys, now, capacity, period)
+  local start = now - now % period
+  local stored = tonumber(redis.call("HGET", keys[1], "start"))
+  local current = tonumber(redis.call("HGET", keys[1], "current")) or 0
+  local previous = tonumber(redis.call("HGET", keys[1], "previous")) or 0
+
+  if stored ~= start then
+    if stored == start - period then
+      previous = current
+    else
+      previous = 0
+    end
+    current = 0
+  end
+
+  local weight = 1 - (now - start) / period
+  local allowed = previous * weight + current + 1 <= capacity
+  if allowed then
+    current = current + 1
+  end
+
+  -- The next request fits once the previous window has slid far enough
+  -- out, or if the current window alone is full, into the next window.
+  local retryAt
+  local room = capacity - 1 - current
+  if room >= 0 then
+    retryAt = start + period * (1 - room / previous)
+  else
+    retryAt = start + period + period * (1 - (capacity - 1) / current)
+  end
+
+  local left, wait = result(capacity - (previous * weight + current), retryAt - now)
+  return allowed, left, wait, function()
+    redis.call("HSET", keys[1], "start", int(start), "current", current, "previous", previous)
+    redis.call("PEXPIRE", keys[1], ttl(start + 2 * period - now))
+  end
+end
+
+-- Generic cell rate algorithm: requests are spaced period/capacity apart,
+-- with a burst of up to capacity requests.
+algorithms.gcra = function(keys, now, capacity, period)
+  local interval = math.max(1, math.floor(period / capacity))
+  local tat = math.max(tonumber(redis.call("GET", keys[1]) or "0") or 0, now)
+
+  local allowed = tat + interval - now <= period
+  if allowed then
+    tat = tat + interval
+  end
+
+  local remaining = math.floor((period - (tat - now)) / interval)
+  local left, wait = result(remaining, tat + interval - now - period)
+  return allowed, left, wait, function()
+    redis.call("SET", keys[1], int(tat), "PX", ttl(tat - now))
+  end
+end
+`
+
+// checkRateLimitsScript checks a request against several buckets and
+// records it in all of them only if every one allows it.
+//
+// KEYS - state keys of every bucket, in the order of the buckets
+// ARGV[1] - current Unix time in microseconds
+// ARGV[2..] - for every bucket, its algorithm name, number of state keys,
+// capacity and period in microseconds
+//
+// Returns for every bucket whether it allows the request as 1 or 0, the
+// requests remaining and the time until the next request fits in microseconds.
+var checkRateLimitsScript = redis.NewScript(algorithmsLua + `
+local now = tonumber(ARGV[1])
+local reply = {}
+local commits = {}
+local admitted = true
+local offset = 1
+
+for i = 2, #ARGV, 4 do
+  local algorithm = algorithms[ARGV[i]]
+  if not algorithm then
+    return redis.error_reply("unknown rate limiting algorithm: " .. ARGV[i])
+  end
+
+  local count = tonumber(ARGV[i + 1])
+  local keys = {unpack(KEYS, offset, offset + count - 1)}
+  offset = offset + count
+
+  local allowed, remaining, retryAfter, commit = algorithm(keys, now, tonumber(ARGV[i + 2]), tonumber(ARGV[i + 3]))
+  admitted = admitted and allowed
+  table.insert(commits, commit)
+  table.insert(reply, allowed and 1 or 0)
+  table.insert(reply, remaining)
+  table.insert(reply, retryAfter)
+end
+
+if admitted then
+  for _, commit in ipairs(commits) do
+    commit()
+  end
+end
+
+return reply
+`)
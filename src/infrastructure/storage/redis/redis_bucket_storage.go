@@ -2,26 +2,113 @@ package redisstorage
 
 import (
 	"context"
-	"errors"
-	"math"
 	"os"
-	"strconv"
+	"strings"
 	"time"
 
+	"github.com/TheJubadze/RateLimiter/infrastructure/tracing"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
 	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
 	"github.com/go-redis/redis/v8"
 )
 
+// moveBucketScript moves every state key of a bucket to the matching key
+// of another bucket. If the destination key already exists, the two are
+// merged so the requests recorded in either still count: the fuller value
+// is kept, see suffixMerges, and the longer time to live.
+//
+// KEYS[1..n] - source state keys
+// KEYS[n+1..2n] - destination state keys
+// ARGV[1..n] - how every state key is merged
+var moveBucketScript = redis.NewScript(`
+local n = #KEYS / 2
+for i = 1, n do
+  local from, to = KEYS[i], KEYS[n + i]
+  if redis.call("EXISTS", from) == 1 then
+    if redis.call("EXISTS", to) == 0 then
+      redis.call("RENAME", from, to)
+    else
+      local fromTTL, toTTL = redis.call("PTTL", from), redis.call("PTTL", to)
+      local merge = ARGV[i]
+      if merge == "max" or merge == "min" then
+        local a, b = tonumber(redis.call("GET", from)), tonumber(redis.call("GET", to))
+        if a and (not b or (merge == "max" and a > b) or (merge == "min" and a < b)) then
+          redis.call("SET", to, redis.call("GET", from))
+        end
+      elseif merge == "window" then
+        -- The later window wins, the counts of the same window are merged
+        local a = tonumber(redis.call("HGET", from, "start")) or 0
+        local b = tonumber(redis.call("HGET", to, "start")) or 0
+        if a > b then
+          redis.call("RENAME", from, to)
+        elseif a == b then
+          local fields = redis.call("HGETALL", from)
+          for j = 1, #fields, 2 do
+            local count = tonumber(fields[j + 1]) or 0
+            if fields[j] ~= "start" and count > (tonumber(redis.call("HGET", to, fields[j])) or 0) then
+              redis.call("HSET", to, fields[j], fields[j + 1])
+            end
+          end
+        end
+      elseif merge == "log" then
+        redis.call("ZUNIONSTORE", to, 2, to, from, "AGGREGATE", "MAX")
+      end
+      redis.call("DEL", from)
+      if fromTTL == -1 or toTTL == -1 then
+        redis.call("PERSIST", to)
+      else
+        redis.call("PEXPIRE", to, math.max(fromTTL, toTTL))
+      end
+    end
+  end
+end
+return 1
+`)
+
+// migrateLegacyBucketScript copies a leaky bucket written before key
+// namespacing to new keys unless they already hold one, then removes it.
+//
+// KEYS[1] - legacy bucket count key
+// KEYS[2] - legacy bucket last leak timestamp key
+// KEYS[3..] - pairs of new count and last leak timestamp keys
+var migrateLegacyBucketScript = redis.NewScript(`
+local count = redis.call("GET", KEYS[1])
+local lastLeak = redis.call("GET", KEYS[2])
+local ttl = redis.call("PTTL", KEYS[1])
+
+if count then
+  for i = 3, #KEYS, 2 do
+    if redis.call("EXISTS", KEYS[i]) == 0 then
+      redis.call("SET", KEYS[i], count)
+      if lastLeak then
+        redis.call("SET", KEYS[i + 1], lastLeak)
+      end
+      if ttl > 0 then
+        redis.call("PEXPIRE", KEYS[i], ttl)
+        redis.call("PEXPIRE", KEYS[i + 1], ttl)
+      end
+    end
+  end
+end
+
+redis.call("DEL", KEYS[1], KEYS[2])
+return 1
+`)
+
 type RedisBucketStorage struct {
 	logger logger.Logger
+	clock  clock.Clock
 	client *redis.Client
 }
 
-func NewRedisBucketStorage(logger logger.Logger, redisAddr string) *RedisBucketStorage {
+func NewRedisBucketStorage(logger logger.Logger, clock clock.Clock, redisAddr string) *RedisBucketStorage {
 	// Initialize Redis client
 	client := redis.NewClient(&redis.Options{
 		Addr: redisAddr,
 	})
+	// Commands sent with the context of a traced call get spans of their own
+	client.AddHook(oteltracing.RedisHook())
 	pong, err := client.Ping(context.Background()).Result()
 	if err != nil {
 		logger.Fatalf("Failed to connect to Redis: %v", err)
@@ -31,73 +118,115 @@ func NewRedisBucketStorage(logger logger.Logger, redisAddr string) *RedisBucketS
 
 	return &RedisBucketStorage{
 		logger: logger,
+		clock:  clock,
 		client: client,
 	}
 }
 
-func (r *RedisBucketStorage) CheckRateLimit(ctx context.Context, key string, capacity int, leakRate time.Duration) (bool, error) {
-	now := time.Now().Unix()
-
-	// Get the last request count and timestamp from Redis
-	pipe := r.client.TxPipeline()
+// Ping reports whether Redis is reachable.
+func (r *RedisBucketStorage) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
 
-	// Fetch bucket count and last updated time (if present)
-	countCmd := pipe.Get(ctx, key+":count")
-	lastLeakCmd := pipe.Get(ctx, key+":lastLeak")
+// Close closes the connections to Redis.
+func (r *RedisBucketStorage) Close() error {
+	return r.client.Close()
+}
 
-	_, err := pipe.Exec(ctx)
-	if err != nil && !errors.Is(err, redis.Nil) {
-		return false, err
+func (r *RedisBucketStorage) CheckRateLimit(ctx context.Context, key string, limit bucket.Limit) (bucket.Result, error) {
+	results, err := r.CheckRateLimits(ctx, []bucket.Check{{Key: key, Limit: limit}})
+	if err != nil {
+		return bucket.Result{}, err
 	}
+	return results[0], nil
+}
 
-	// Parse the count and last leak timestamp (defaults to 0 if not present)
-	count, _ := strconv.ParseInt(countCmd.Val(), 10, 64)
-	lastLeak, _ := strconv.ParseInt(lastLeakCmd.Val(), 10, 64)
+// CheckRateLimits checks all buckets in one script run, so the request is
+// recorded in all of them or in none.
+func (r *RedisBucketStorage) CheckRateLimits(ctx context.Context, checks []bucket.Check) ([]bucket.Result, error) {
+	if len(checks) == 0 {
+		return nil, nil
+	}
 
-	// Calculate the time since last leak
-	if lastLeak == 0 {
-		lastLeak = now
+	var keys []string
+	args := []interface{}{r.clock.Now().UnixMicro()}
+	for _, check := range checks {
+		limit := check.Limit
+		if err := limit.Validate(); err != nil {
+			return nil, err
+		}
+		suffixes := algorithmSuffixes[limit.Algorithm]
+		keys = append(keys, withSuffixes(check.Key, suffixes)...)
+		args = append(args, string(limit.Algorithm), len(suffixes), limit.Capacity, limit.Period.Microseconds())
 	}
 
-	elapsed := now - lastLeak
-	leakedRequests := int64(math.Floor(float64(elapsed) / leakRate.Seconds() * float64(capacity)))
+	// Run is EVALSHA with the cached script SHA, falling back to EVAL on NOSCRIPT
+	reply, err := checkRateLimitsScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
 
-	// Subtract the leaked requests from the current count
-	count -= leakedRequests
-	if count < 0 {
-		count = 0
+	results := make([]bucket.Result, len(checks))
+	for i := range checks {
+		results[i] = bucket.Result{
+			Allowed:    reply[3*i] == 1,
+			Remaining:  int(reply[3*i+1]),
+			RetryAfter: time.Duration(reply[3*i+2]) * time.Microsecond,
+		}
 	}
+	bucket.LogResults(r.logger, checks, results)
 
-	// If the bucket is still below capacity, allow the request and update the count
-	if count < int64(capacity) {
-		count++
+	return results, nil
+}
 
-		pipe := r.client.TxPipeline()
+func (r *RedisBucketStorage) ResetBucket(ctx context.Context, key string) error {
+	// Reset the bucket state for every algorithm
+	return r.client.Del(ctx, withSuffixes(key, allSuffixes())...).Err()
+}
 
-		// Update the count and the last leak timestamp
-		pipe.Set(ctx, key+":count", count, 0)
-		pipe.Set(ctx, key+":lastLeak", now, 0)
+// MoveBucket carries the bucket stored under from over to the key to,
+// merging it with the bucket to already has, if any. The bucket under from
+// is removed.
+func (r *RedisBucketStorage) MoveBucket(ctx context.Context, from, to string) error {
+	suffixes := allSuffixes()
+	keys := append(withSuffixes(from, suffixes), withSuffixes(to, suffixes)...)
+	merges := make([]interface{}, len(suffixes))
+	for i, suffix := range suffixes {
+		merges[i] = suffixMerges[suffix]
+	}
+	return moveBucketScript.Run(ctx, r.client, keys, merges...).Err()
+}
 
-		_, err := pipe.Exec(ctx)
-		if err != nil {
-			return false, err
+// MigrateLegacyKeys moves every bucket whose key is mapped to new keys by
+// newKeys. Keys for which newKeys returns nil are left as they are.
+// It returns the number of migrated buckets.
+func (r *RedisBucketStorage) MigrateLegacyKeys(ctx context.Context, newKeys func(key string) []string) (int, error) {
+	migrated := 0
+	iter := r.client.Scan(ctx, 0, "*:count", 0).Iterator()
+	for iter.Next(ctx) {
+		key := strings.TrimSuffix(iter.Val(), ":count")
+		targets := newKeys(key)
+		if len(targets) == 0 {
+			continue
 		}
 
-		r.logger.Printf("Key: %s, count: %d, lastLeak: %s", key, count, time.Unix(lastLeak, 0).Format("2006-01-02 15:04:05"))
-		return true, nil
+		keys := []string{key + ":count", key + ":lastLeak"}
+		for _, target := range targets {
+			keys = append(keys, target+":count", target+":lastLeak")
+		}
+		if err := migrateLegacyBucketScript.Run(ctx, r.client, keys).Err(); err != nil {
+			return migrated, err
+		}
+		migrated++
 	}
 
-	// If the count exceeds the capacity, reject the request
-	r.logger.Printf("Key: %s, count: %d - rate limit exceeded", key, count)
-	return false, nil
+	return migrated, iter.Err()
 }
 
-func (r *RedisBucketStorage) ResetBucket(ctx context.Context, key string) error {
-	// Reset the count and lastLeak for the bucket
-	pipe := r.client.TxPipeline()
-	pipe.Del(ctx, key+":count")
-	pipe.Del(ctx, key+":lastLeak")
-
-	_, err := pipe.Exec(ctx)
-	return err
+func withSuffixes(key string, suffixes []string) []string {
+	keys := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		keys = append(keys, key+suffix)
+	}
+	return keys
 }
@@ -0,0 +1,224 @@
+package redisstorage_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/clock"
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/infrastructure/storage/redis"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket/buckettest"
+	"github.com/TheJubadze/RateLimiter/internal/bucketkey"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func leakyBucket(capacity int, period time.Duration) bucket.Limit {
+	return bucket.Limit{Algorithm: bucket.LeakyBucket, Capacity: capacity, Period: period}
+}
+
+func newStorage(t *testing.T, clock clock.Clock) (*redisstorage.RedisBucketStorage, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	return redisstorage.NewRedisBucketStorage(logruslogger.NewLogrusLogger("panic"), clock, srv.Addr()), srv
+}
+
+func TestConformance(t *testing.T) {
+	buckettest.RunConformanceTests(t, func(t *testing.T, clock clock.Clock) bucket.Storage {
+		t.Helper()
+		storage, _ := newStorage(t, clock)
+		return storage
+	})
+}
+
+func TestCheckRateLimitConcurrent(t *testing.T) {
+	const (
+		capacity = 10
+		requests = 50
+	)
+
+	storage, _ := newStorage(t, systemclock.New())
+
+	var admitted atomic.Int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			result, err := storage.CheckRateLimit(context.Background(), "user", leakyBucket(capacity, time.Hour))
+			assert.NoError(t, err)
+			if result.Allowed {
+				admitted.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int64(capacity), admitted.Load())
+}
+
+func TestCheckRateLimitScriptFlushed(t *testing.T) {
+	storage, srv := newStorage(t, systemclock.New())
+
+	result, err := storage.CheckRateLimit(context.Background(), "user", leakyBucket(1, time.Hour))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// The cached SHA is gone, so the storage has to fall back from EVALSHA to EVAL
+	srv.FlushAll()
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+	require.NoError(t, client.ScriptFlush(context.Background()).Err())
+
+	result, err = storage.CheckRateLimit(context.Background(), "user", leakyBucket(1, time.Hour))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = storage.CheckRateLimit(context.Background(), "user", leakyBucket(1, time.Hour))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestMigrateLegacyKeys(t *testing.T) {
+	storage, srv := newStorage(t, systemclock.New())
+	keys := bucketkey.NewBuilder(systemclock.New(), "secret", nil, time.Time{}, 0, false)
+
+	require.NoError(t, srv.Set("user:count", "3"))
+	require.NoError(t, srv.Set("user:lastLeak", "1700000000"))
+	require.NoError(t, srv.Set("10.0.0.1:count", "5"))
+	require.NoError(t, srv.Set("10.0.0.1:lastLeak", "1700000000"))
+	require.NoError(t, srv.Set(keys.Login("other")+":count", "1"))
+
+	migrated, err := storage.MigrateLegacyKeys(context.Background(), keys.FromLegacy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+
+	assert.False(t, srv.Exists("user:count"))
+	assert.False(t, srv.Exists("10.0.0.1:count"))
+	for key, count := range map[string]string{
+		keys.Login("user"):    "3",
+		keys.Password("user"): "3",
+		keys.IP("10.0.0.1"):   "5",
+		keys.Login("other"):   "1",
+	} {
+		got, err := srv.Get(key + ":count")
+		require.NoError(t, err)
+		assert.Equal(t, count, got, key)
+	}
+
+	// Running it again is a no-op
+	migrated, err = storage.MigrateLegacyKeys(context.Background(), keys.FromLegacy)
+	require.NoError(t, err)
+	assert.Zero(t, migrated)
+}
+
+func TestCheckRateLimitExpiresDrainedBucket(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1700000000, 0))
+	storage, srv := newStorage(t, fakeClock)
+
+	for i := 0; i < 2; i++ {
+		result, err := storage.CheckRateLimit(context.Background(), "user", leakyBucket(2, time.Minute))
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	result, err := storage.CheckRateLimit(context.Background(), "user", leakyBucket(2, time.Minute))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// A full bucket drains in one leak period
+	assert.Equal(t, time.Minute, srv.TTL("user:count"))
+	assert.Equal(t, time.Minute, srv.TTL("user:lastLeak"))
+
+	fakeClock.Advance(time.Minute)
+	srv.FastForward(time.Minute)
+	assert.False(t, srv.Exists("user:count"))
+	assert.False(t, srv.Exists("user:lastLeak"))
+
+	result, err = storage.CheckRateLimit(context.Background(), "user", leakyBucket(2, time.Minute))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	count, err := srv.Get("user:count")
+	require.NoError(t, err)
+	assert.Equal(t, "1", count)
+	assert.Equal(t, 30*time.Second, srv.TTL("user:count"))
+}
+
+func TestCheckRateLimitCarriesOverFractionalLeaks(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1700000000, 0))
+	storage, _ := newStorage(t, fakeClock)
+	limit := leakyBucket(10, time.Second)
+
+	admitted := func(requests int) int {
+		count := 0
+		for i := 0; i < requests; i++ {
+			result, err := storage.CheckRateLimit(context.Background(), "user", limit)
+			require.NoError(t, err)
+			if result.Allowed {
+				count++
+			}
+		}
+		return count
+	}
+
+	assert.Equal(t, 10, admitted(11))
+
+	// 2.5 requests have leaked, the half is kept for later
+	fakeClock.Advance(250 * time.Millisecond)
+	assert.Equal(t, 2, admitted(3))
+
+	fakeClock.Advance(50 * time.Millisecond)
+	assert.Equal(t, 1, admitted(2))
+}
+
+func TestMoveBucketMergesWithExistingBucket(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1700000000, 0))
+	storage, srv := newStorage(t, fakeClock)
+	limit := leakyBucket(5, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		_, err := storage.CheckRateLimit(context.Background(), "old", limit)
+		require.NoError(t, err)
+	}
+	fakeClock.Advance(10 * time.Minute)
+	srv.FastForward(10 * time.Minute)
+	_, err := storage.CheckRateLimit(context.Background(), "new", limit)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.MoveBucket(context.Background(), "old", "new"))
+
+	// The fuller level, the later leak and the longer time to live are kept
+	assert.False(t, srv.Exists("old:count"))
+	assert.False(t, srv.Exists("old:lastLeak"))
+	count, err := srv.Get("new:count")
+	require.NoError(t, err)
+	assert.Equal(t, "5", count)
+	lastLeak, err := srv.Get("new:lastLeak")
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(fakeClock.Now().UnixMicro(), 10), lastLeak)
+	assert.Equal(t, 50*time.Minute, srv.TTL("new:count"))
+	assert.Equal(t, 50*time.Minute, srv.TTL("new:lastLeak"))
+
+	result, err := storage.CheckRateLimit(context.Background(), "new", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestPing(t *testing.T) {
+	storage, srv := newStorage(t, systemclock.New())
+
+	assert.NoError(t, storage.Ping(context.Background()))
+
+	srv.Close()
+	assert.Error(t, storage.Ping(context.Background()))
+}
@@ -0,0 +1,168 @@
+package oteltracing
+
+import (
+	"context"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/go-redis/redis/extra/redisotel/v8"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"google.golang.org/grpc"
+)
+
+const tracerName = "github.com/TheJubadze/RateLimiter/infrastructure/tracing"
+
+// NewTracerProvider creates a provider exporting the spans over OTLP/gRPC
+// to the collector at endpoint, in batches. It samples sampleRatio of the
+// traces started by the service, and follows the decision of the callers
+// for the traces they started.
+func NewTracerProvider(ctx context.Context, endpoint string, insecure bool, sampleRatio float64) (*sdktrace.TracerProvider, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("rate-limiter"))),
+	), nil
+}
+
+// ServerOption makes the gRPC server start a span for every call, in the
+// trace the caller propagated with the W3C trace context headers if any.
+func ServerOption(provider trace.TracerProvider) grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(
+		otelgrpc.WithTracerProvider(provider),
+		otelgrpc.WithPropagators(propagation.TraceContext{}),
+	))
+}
+
+// CallTracerProvider starts the spans with the provider of the call they
+// are part of, so nothing is recorded outside of a trace. It lets the
+// instrumentation of the Redis and Postgres clients, which is set up once,
+// follow the provider the server was started with.
+func CallTracerProvider() trace.TracerProvider {
+	return callTracerProvider{}
+}
+
+type callTracerProvider struct {
+	embedded.TracerProvider
+}
+
+func (callTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return callTracer{name: name, opts: opts}
+}
+
+type callTracer struct {
+	embedded.Tracer
+	name string
+	opts []trace.TracerOption
+}
+
+func (t callTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(t.name, t.opts...).Start(ctx, spanName, opts...)
+}
+
+// RedisHook starts a span for every command sent to Redis.
+func RedisHook() redis.Hook {
+	return redisotel.NewTracingHook(redisotel.WithTracerProvider(CallTracerProvider()))
+}
+
+// TraceBucketStorage starts a span for every call to the storage.
+func TraceBucketStorage(storage bucket.Storage, backend string) bucket.Storage {
+	return &bucketStorage{Storage: storage, backend: attribute.String("ratelimiter.bucket.backend", backend)}
+}
+
+type bucketStorage struct {
+	bucket.Storage
+	backend attribute.KeyValue
+}
+
+func (s *bucketStorage) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	// Spans are started with the provider of the call, so nothing is
+	// recorded outside of a trace
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName)
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(append(attrs, s.backend)...))
+}
+
+func (s *bucketStorage) CheckRateLimit(ctx context.Context, key string, limit bucket.Limit) (bucket.Result, error) {
+	ctx, span := s.start(ctx, "bucket.CheckRateLimit", attribute.String("ratelimiter.bucket.algorithm", string(limit.Algorithm)))
+	defer span.End()
+
+	result, err := s.Storage.CheckRateLimit(ctx, key, limit)
+	recordError(span, err)
+	span.SetAttributes(attribute.Bool("ratelimiter.bucket.allowed", result.Allowed))
+	return result, err
+}
+
+func (s *bucketStorage) CheckRateLimits(ctx context.Context, checks []bucket.Check) ([]bucket.Result, error) {
+	// The checks are described by slices, in the order of the checks
+	algorithms := make([]string, len(checks))
+	for i, check := range checks {
+		algorithms[i] = string(check.Limit.Algorithm)
+	}
+	ctx, span := s.start(ctx, "bucket.CheckRateLimits",
+		attribute.Int("ratelimiter.bucket.checks", len(checks)),
+		attribute.StringSlice("ratelimiter.bucket.check.algorithms", algorithms),
+	)
+	defer span.End()
+
+	results, err := s.Storage.CheckRateLimits(ctx, checks)
+	recordError(span, err)
+	allowed := err == nil
+	checksAllowed := make([]bool, len(results))
+	remaining := make([]int, len(results))
+	for i, result := range results {
+		allowed = allowed && result.Allowed
+		checksAllowed[i] = result.Allowed
+		remaining[i] = result.Remaining
+	}
+	span.SetAttributes(attribute.Bool("ratelimiter.bucket.allowed", allowed))
+	if err == nil {
+		span.SetAttributes(
+			attribute.BoolSlice("ratelimiter.bucket.check.allowed", checksAllowed),
+			attribute.IntSlice("ratelimiter.bucket.check.remaining", remaining),
+		)
+	}
+	return results, err
+}
+
+func (s *bucketStorage) ResetBucket(ctx context.Context, key string) error {
+	ctx, span := s.start(ctx, "bucket.ResetBucket")
+	defer span.End()
+
+	err := s.Storage.ResetBucket(ctx, key)
+	recordError(span, err)
+	return err
+}
+
+func (s *bucketStorage) MoveBucket(ctx context.Context, from, to string) error {
+	ctx, span := s.start(ctx, "bucket.MoveBucket")
+	defer span.End()
+
+	err := s.Storage.MoveBucket(ctx, from, to)
+	recordError(span, err)
+	return err
+}
+
+// recordError marks the span as failed with the error, if any.
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,144 @@
+package oteltracing_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/tracing"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTraceBucketStorage(t *testing.T) {
+	provider, exporter := newProvider()
+	mockStorage := new(bucket.MockBucketStorage)
+	mockStorage.On("CheckRateLimits", mock.Anything, mock.Anything).Return([]bucket.Result{{Allowed: true, Remaining: 3}, {Allowed: false}}, nil)
+	mockStorage.On("MoveBucket", mock.Anything, "old", "new").Return(errors.New("connection refused"))
+	storage := oteltracing.TraceBucketStorage(mockStorage, "redis")
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "Authorize")
+	_, err := storage.CheckRateLimits(ctx, []bucket.Check{
+		{Key: "a", Limit: bucket.Limit{Algorithm: bucket.LeakyBucket}},
+		{Key: "b", Limit: bucket.Limit{Algorithm: bucket.FixedWindow}},
+	})
+	require.NoError(t, err)
+	err = storage.MoveBucket(ctx, "old", "new")
+	require.Error(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	check := spans[0]
+	assert.Equal(t, "bucket.CheckRateLimits", check.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), check.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, check.SpanKind)
+	assert.Equal(t, attribute.StringValue("redis"), attributes(check)["ratelimiter.bucket.backend"])
+	assert.Equal(t, attribute.IntValue(2), attributes(check)["ratelimiter.bucket.checks"])
+	assert.Equal(t, attribute.BoolValue(false), attributes(check)["ratelimiter.bucket.allowed"])
+	assert.Equal(t, attribute.StringSliceValue([]string{"leaky_bucket", "fixed_window"}), attributes(check)["ratelimiter.bucket.check.algorithms"])
+	assert.Equal(t, attribute.BoolSliceValue([]bool{true, false}), attributes(check)["ratelimiter.bucket.check.allowed"])
+	assert.Equal(t, attribute.IntSliceValue([]int{3, 0}), attributes(check)["ratelimiter.bucket.check.remaining"])
+
+	move := spans[1]
+	assert.Equal(t, "bucket.MoveBucket", move.Name)
+	assert.Equal(t, codes.Error, move.Status.Code)
+	assert.Equal(t, "connection refused", move.Status.Description)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestTraceBucketStorageOutsideOfTrace(t *testing.T) {
+	mockStorage := new(bucket.MockBucketStorage)
+	mockStorage.On("ResetBucket", mock.Anything, "key").Return(nil)
+	storage := oteltracing.TraceBucketStorage(mockStorage, "memory")
+
+	assert.NoError(t, storage.ResetBucket(context.Background(), "key"))
+	mockStorage.AssertExpectations(t)
+}
+
+func TestRedisHook(t *testing.T) {
+	provider, exporter := newProvider()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	client.AddHook(oteltracing.RedisHook())
+
+	// Outside of a trace, nothing is recorded
+	require.NoError(t, client.Set(context.Background(), "key", "value", 0).Err())
+	assert.Empty(t, exporter.GetSpans())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "Authorize")
+	require.NoError(t, client.Get(ctx, "key").Err())
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	get := spans[0]
+	assert.Equal(t, "get", get.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), get.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, get.SpanKind)
+	assert.Equal(t, attribute.StringValue("redis"), attributes(get)["db.system"])
+}
+
+func TestServerOptionContinuesTraceOfCaller(t *testing.T) {
+	provider, exporter := newProvider()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(oteltracing.ServerOption(provider))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The caller propagates its trace with the traceparent header
+	callerProvider, _ := newProvider()
+	ctx, caller := callerProvider.Tracer("test").Start(context.Background(), "caller")
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "traceparent", carrier.Get("traceparent"))
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	caller.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "grpc.health.v1.Health/Check", spans[0].Name)
+	assert.Equal(t, caller.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+	assert.Equal(t, caller.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+}
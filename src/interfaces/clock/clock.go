@@ -0,0 +1,8 @@
+package clock
+
+import "time"
+
+// Clock tells the current time, so time-dependent logic can be driven by tests.
+type Clock interface {
+	Now() time.Time
+}
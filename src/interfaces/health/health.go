@@ -0,0 +1,11 @@
+package health
+
+import (
+	"context"
+)
+
+// Checker is a dependency the service can't serve requests without.
+type Checker interface {
+	// Ping reports whether the dependency is reachable.
+	Ping(ctx context.Context) error
+}
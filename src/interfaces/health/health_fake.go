@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FakeChecker is a Checker whose reachability and latency are set by the test.
+type FakeChecker struct {
+	mu    sync.Mutex
+	err   error
+	delay time.Duration
+}
+
+func (c *FakeChecker) Ping(ctx context.Context) error {
+	c.mu.Lock()
+	err, delay := c.err, c.delay
+	c.mu.Unlock()
+
+	if delay == 0 {
+		return err
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetError makes Ping return err, nil for a reachable dependency.
+func (c *FakeChecker) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// SetDelay makes Ping take delay to answer, or fail once its context is done.
+func (c *FakeChecker) SetDelay(delay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delay = delay
+}
@@ -1,12 +1,87 @@
 package ipfilter
 
+import (
+	"context"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+)
+
+// Entry is a listed network along with the list it is on.
+type Entry struct {
+	List string
+	database.Entry
+}
+
+// ListedNetwork is a network on one of the lists.
+type ListedNetwork struct {
+	List    string
+	Network string
+}
+
+// Overlap is a pair of networks one of which holds all of the other. They
+// may be the same network, on both lists.
+type Overlap struct {
+	Supernet ListedNetwork
+	Subnet   ListedNetwork
+}
+
+// Precedence decides between the rules of the same priority matching a
+// request.
+type Precedence string
+
+const (
+	// ListPrecedence puts the whitelist first, then the most specific
+	// network. It is how the lists always behaved.
+	ListPrecedence Precedence = "list"
+	// SpecificityPrecedence puts the most specific network first, then the
+	// whitelist.
+	SpecificityPrecedence Precedence = "specificity"
+)
+
+// Decision is the rule deciding about the requests from an IP, along with
+// the network it is the rule of.
+type Decision struct {
+	List    string
+	Network string
+	database.Rule
+}
+
 type Service interface {
+	// Evaluate returns the rule deciding about the requests from an IP, and
+	// whether any rule matches it. It fails if the lists can't be read.
+	// Without the lists cached, they are read with ctx.
+	Evaluate(ctx context.Context, ip string) (Decision, bool, error)
 	IsIPWhitelisted(ip string) bool
 	IsIPBlacklisted(ip string) bool
 	IsNetworkWhitelisted(network string) (bool, error)
 	IsNetworkBlacklisted(network string) (bool, error)
-	AddToWhitelist(subnet string) error
+	// AddToWhitelist whitelists a network for ttl, or for good if ttl is zero.
+	// The rule allows requests unless it sets another action.
+	AddToWhitelist(subnet string, ttl time.Duration, rule database.Rule, metadata database.Metadata) error
 	RemoveFromWhitelist(subnet string) (bool, error)
-	AddToBlacklist(subnet string) error
+	// AddToBlacklist blacklists a network for ttl, or for good if ttl is zero.
+	// The rule denies requests unless it sets another action.
+	AddToBlacklist(subnet string, ttl time.Duration, rule database.Rule, metadata database.Metadata) error
 	RemoveFromBlacklist(subnet string) (bool, error)
+	// ImportWhitelist whitelists the networks, or updates them if they already
+	// are, all at once. If replace is set, the networks not imported are
+	// removed from the whitelist.
+	ImportWhitelist(entries []database.Entry, replace bool) (database.ImportResult, error)
+	// ImportBlacklist blacklists the networks, or updates them if they already
+	// are, all at once. If replace is set, the networks not imported are
+	// removed from the blacklist.
+	ImportBlacklist(entries []database.Entry, replace bool) (database.ImportResult, error)
+	// ListWhitelist returns a page of the whitelisted networks.
+	ListWhitelist(query database.ListQuery) (database.Page, error)
+	// ListBlacklist returns a page of the blacklisted networks.
+	ListBlacklist(query database.ListQuery) (database.Page, error)
+	// FindOverlaps returns how a network would overlap the listed networks
+	// if it was added to the list, leaving out the network itself.
+	FindOverlaps(list, network string) ([]Overlap, error)
+	// CheckConsistency returns every pair of overlapping listed networks.
+	CheckConsistency() ([]Overlap, error)
+	// Inspect returns the listed networks an IP or network falls into,
+	// whitelist first, shortest prefix first.
+	Inspect(network string) ([]Entry, error)
 }
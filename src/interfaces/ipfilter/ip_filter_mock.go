@@ -1,6 +1,10 @@
 package ipfilter
 
 import (
+	"context"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,6 +12,11 @@ type MockIPFilterService struct {
 	mock.Mock
 }
 
+func (m *MockIPFilterService) Evaluate(ctx context.Context, ip string) (Decision, bool, error) {
+	args := m.Called(ctx, ip)
+	return args.Get(0).(Decision), args.Bool(1), args.Error(2)
+}
+
 func (m *MockIPFilterService) IsIPWhitelisted(ip string) bool {
 	args := m.Called(ip)
 	return args.Bool(0)
@@ -28,8 +37,8 @@ func (m *MockIPFilterService) IsNetworkBlacklisted(network string) (bool, error)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockIPFilterService) AddToWhitelist(subnet string) error {
-	args := m.Called(subnet)
+func (m *MockIPFilterService) AddToWhitelist(subnet string, ttl time.Duration, rule database.Rule, metadata database.Metadata) error {
+	args := m.Called(subnet, ttl, rule, metadata)
 	return args.Error(0)
 }
 
@@ -38,8 +47,8 @@ func (m *MockIPFilterService) RemoveFromWhitelist(subnet string) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockIPFilterService) AddToBlacklist(subnet string) error {
-	args := m.Called(subnet)
+func (m *MockIPFilterService) AddToBlacklist(subnet string, ttl time.Duration, rule database.Rule, metadata database.Metadata) error {
+	args := m.Called(subnet, ttl, rule, metadata)
 	return args.Error(0)
 }
 
@@ -47,3 +56,41 @@ func (m *MockIPFilterService) RemoveFromBlacklist(subnet string) (bool, error) {
 	args := m.Called(subnet)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockIPFilterService) ImportWhitelist(entries []database.Entry, replace bool) (database.ImportResult, error) {
+	args := m.Called(entries, replace)
+	return args.Get(0).(database.ImportResult), args.Error(1)
+}
+
+func (m *MockIPFilterService) ImportBlacklist(entries []database.Entry, replace bool) (database.ImportResult, error) {
+	args := m.Called(entries, replace)
+	return args.Get(0).(database.ImportResult), args.Error(1)
+}
+
+func (m *MockIPFilterService) ListWhitelist(query database.ListQuery) (database.Page, error) {
+	args := m.Called(query)
+	return args.Get(0).(database.Page), args.Error(1)
+}
+
+func (m *MockIPFilterService) ListBlacklist(query database.ListQuery) (database.Page, error) {
+	args := m.Called(query)
+	return args.Get(0).(database.Page), args.Error(1)
+}
+
+func (m *MockIPFilterService) FindOverlaps(list, network string) ([]Overlap, error) {
+	args := m.Called(list, network)
+	overlaps, _ := args.Get(0).([]Overlap)
+	return overlaps, args.Error(1)
+}
+
+func (m *MockIPFilterService) CheckConsistency() ([]Overlap, error) {
+	args := m.Called()
+	overlaps, _ := args.Get(0).([]Overlap)
+	return overlaps, args.Error(1)
+}
+
+func (m *MockIPFilterService) Inspect(network string) ([]Entry, error) {
+	args := m.Called(network)
+	entries, _ := args.Get(0).([]Entry)
+	return entries, args.Error(1)
+}
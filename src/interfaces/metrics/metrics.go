@@ -0,0 +1,24 @@
+package metrics
+
+// Reason is why a request was authorized or not.
+type Reason string
+
+const (
+	// Whitelist is a request allowed or denied by the rule of its IP on the
+	// whitelist.
+	Whitelist Reason = "whitelist"
+	// Blacklist is a request allowed or denied by the rule of its IP on the
+	// blacklist.
+	Blacklist Reason = "blacklist"
+	// WithinLimits is a request no limit rejected.
+	WithinLimits  Reason = "within_limits"
+	LoginLimit    Reason = "login_limit"
+	PasswordLimit Reason = "password_limit"
+	IPLimit       Reason = "ip_limit"
+)
+
+// Recorder records what the service decides, for monitoring.
+type Recorder interface {
+	// AuthorizeDecision counts an Authorize call by its outcome and reason.
+	AuthorizeDecision(authorized bool, reason Reason)
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"sync"
+)
+
+// Decision is an Authorize decision recorded by the FakeRecorder.
+type Decision struct {
+	Authorized bool
+	Reason     Reason
+}
+
+// FakeRecorder is a Recorder that keeps the decisions in memory.
+type FakeRecorder struct {
+	mu        sync.Mutex
+	decisions []Decision
+}
+
+func (r *FakeRecorder) AuthorizeDecision(authorized bool, reason Reason) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decisions = append(r.decisions, Decision{Authorized: authorized, Reason: reason})
+}
+
+// Decisions returns the decisions recorded so far.
+func (r *FakeRecorder) Decisions() []Decision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Decision(nil), r.decisions...)
+}
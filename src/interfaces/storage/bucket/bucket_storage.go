@@ -2,10 +2,105 @@ package bucket
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+)
+
+var ErrUnknownAlgorithm = errors.New("unknown rate limiting algorithm")
+
+// Algorithm names a rate limiting algorithm a Storage can apply to a bucket.
+type Algorithm string
+
+const (
+	LeakyBucket          Algorithm = "leaky_bucket"
+	TokenBucket          Algorithm = "token_bucket"
+	FixedWindow          Algorithm = "fixed_window"
+	SlidingWindowLog     Algorithm = "sliding_window_log"
+	SlidingWindowCounter Algorithm = "sliding_window_counter"
+	GCRA                 Algorithm = "gcra"
 )
 
+// Algorithms lists every supported algorithm.
+var Algorithms = []Algorithm{LeakyBucket, TokenBucket, FixedWindow, SlidingWindowLog, SlidingWindowCounter, GCRA}
+
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, algorithm := range Algorithms {
+		if string(algorithm) == name {
+			return algorithm, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+}
+
+// Limit describes how a bucket is limited: at most Capacity requests per
+// Period, enforced by Algorithm.
+type Limit struct {
+	Algorithm Algorithm
+	Capacity  int
+	Period    time.Duration
+}
+
+func (l Limit) Validate() error {
+	if _, err := ParseAlgorithm(string(l.Algorithm)); err != nil {
+		return err
+	}
+	if l.Capacity <= 0 {
+		return fmt.Errorf("invalid capacity: %d", l.Capacity)
+	}
+	if l.Period < time.Microsecond {
+		return fmt.Errorf("invalid period: %s", l.Period)
+	}
+	return nil
+}
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	// Allowed is whether the request fits into the bucket.
+	Allowed bool
+	// Remaining is how many more requests the bucket admits right now.
+	Remaining int
+	// RetryAfter is how long until the bucket admits another request,
+	// zero if it already does.
+	RetryAfter time.Duration
+}
+
+// Check is one bucket a request is checked against.
+type Check struct {
+	Key   string
+	Limit Limit
+}
+
+// LogResults logs what every bucket has remaining when the request fits in
+// all of them, and otherwise only the buckets rejecting it, since the others
+// record nothing.
+func LogResults(logger logger.Logger, checks []Check, results []Result) {
+	admitted := true
+	for _, result := range results {
+		admitted = admitted && result.Allowed
+	}
+	for i, check := range checks {
+		switch {
+		case admitted:
+			logger.Printf("Key: %s, algorithm: %s - allowed, %d remaining", check.Key, check.Limit.Algorithm, results[i].Remaining)
+		case !results[i].Allowed:
+			logger.Printf("Key: %s, algorithm: %s - rate limit exceeded, retry after %s", check.Key, check.Limit.Algorithm, results[i].RetryAfter)
+		}
+	}
+}
+
 type Storage interface {
-	CheckRateLimit(ctx context.Context, key string, limit int, leakRate time.Duration) (bool, error)
+	CheckRateLimit(ctx context.Context, key string, limit Limit) (Result, error)
+	// CheckRateLimits checks a request against several buckets atomically.
+	// The request is recorded in every bucket if all of them allow it, and
+	// in none otherwise. Results are in the order of the checks, each one
+	// as if the request was recorded in its bucket.
+	CheckRateLimits(ctx context.Context, checks []Check) ([]Result, error)
 	ResetBucket(ctx context.Context, key string) error
+	// MoveBucket moves the bucket stored under from to the key to. If to
+	// already has a bucket, the two are merged, keeping the fuller one, so
+	// no recorded request is given back.
+	MoveBucket(ctx context.Context, from, to string) error
 }
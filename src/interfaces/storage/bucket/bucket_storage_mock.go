@@ -2,7 +2,6 @@ package bucket
 
 import (
 	"context"
-	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -11,12 +10,23 @@ type MockBucketStorage struct {
 	mock.Mock
 }
 
-func (m *MockBucketStorage) CheckRateLimit(ctx context.Context, key string, capacity int, leakRate time.Duration) (bool, error) {
-	args := m.Called(ctx, key, capacity, leakRate)
-	return args.Bool(0), args.Error(1)
+func (m *MockBucketStorage) CheckRateLimit(ctx context.Context, key string, limit Limit) (Result, error) {
+	args := m.Called(ctx, key, limit)
+	return args.Get(0).(Result), args.Error(1)
+}
+
+func (m *MockBucketStorage) CheckRateLimits(ctx context.Context, checks []Check) ([]Result, error) {
+	args := m.Called(ctx, checks)
+	results, _ := args.Get(0).([]Result)
+	return results, args.Error(1)
 }
 
 func (m *MockBucketStorage) ResetBucket(ctx context.Context, key string) error {
 	args := m.Called(ctx, key)
 	return args.Error(0)
 }
+
+func (m *MockBucketStorage) MoveBucket(ctx context.Context, from, to string) error {
+	args := m.Called(ctx, from, to)
+	return args.Error(0)
+}
@@ -0,0 +1,268 @@
+package buckettest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start is aligned to the test period, so every test begins at the start
+// of a fixed window.
+var start = time.Unix(0, 0).Add(500000 * time.Hour)
+
+// refills lists how many requests each algorithm admits again after a full
+// bucket had a fifth of a period to recover.
+var refills = map[bucket.Algorithm]int{
+	bucket.LeakyBucket:          1,
+	bucket.TokenBucket:          1,
+	bucket.FixedWindow:          0,
+	bucket.SlidingWindowLog:     0,
+	bucket.SlidingWindowCounter: 0,
+	bucket.GCRA:                 1,
+}
+
+// steadyTotals lists how many requests each algorithm admits over ten
+// periods of two requests every fifth of a period. Buckets admit the initial
+// burst and then one request per interval, windows admit exactly their
+// capacity, and the sliding window counter estimate errs on the safe side.
+var steadyTotals = map[bucket.Algorithm]int{
+	bucket.LeakyBucket:          54,
+	bucket.TokenBucket:          54,
+	bucket.FixedWindow:          50,
+	bucket.SlidingWindowLog:     50,
+	bucket.SlidingWindowCounter: 41,
+	bucket.GCRA:                 54,
+}
+
+// retryAfters lists how long a full bucket of five requests per hour asks
+// to wait. Buckets free up one interval, windows wait for the window to
+// end, and the sliding window counter until the full window has slid out
+// far enough.
+var retryAfters = map[bucket.Algorithm]time.Duration{
+	bucket.LeakyBucket:          12 * time.Minute,
+	bucket.TokenBucket:          12 * time.Minute,
+	bucket.FixedWindow:          time.Hour,
+	bucket.SlidingWindowLog:     time.Hour,
+	bucket.SlidingWindowCounter: 72 * time.Minute,
+	bucket.GCRA:                 12 * time.Minute,
+}
+
+// RunConformanceTests runs the behaviour every bucket.Storage implementation
+// must share against storages created by newStorage, for every algorithm.
+// The storage must take the time from the given clock.
+// Algorithms are tested in parallel, so newStorage must be safe to call
+// concurrently.
+func RunConformanceTests(t *testing.T, newStorage func(t *testing.T, clock clock.Clock) bucket.Storage) {
+	t.Helper()
+
+	for _, algorithm := range bucket.Algorithms {
+		limit := bucket.Limit{Algorithm: algorithm, Capacity: 5, Period: time.Hour}
+		newStorage := func(t *testing.T) (bucket.Storage, *clock.FakeClock) {
+			t.Helper()
+			fakeClock := clock.NewFakeClock(start)
+			return newStorage(t, fakeClock), fakeClock
+		}
+
+		t.Run(string(algorithm), func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("AdmitsUpToCapacity", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "key", limit, limit.Capacity*2))
+			})
+
+			t.Run("KeepsKeysApart", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "first", limit, limit.Capacity+1))
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "second", limit, limit.Capacity+1))
+			})
+
+			t.Run("ResetBucket", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				admitted(t, storage, "key", limit, limit.Capacity)
+				require.NoError(t, storage.ResetBucket(context.Background(), "key"))
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "key", limit, limit.Capacity+1))
+			})
+
+			t.Run("MoveBucket", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				admitted(t, storage, "from", limit, limit.Capacity-1)
+				require.NoError(t, storage.MoveBucket(context.Background(), "from", "to"))
+				assert.Equal(t, 1, admitted(t, storage, "to", limit, limit.Capacity))
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "from", limit, limit.Capacity+1))
+			})
+
+			t.Run("MoveBucketMergesWithDestination", func(t *testing.T) {
+				// Whichever of the two buckets is full, the requests it recorded still count
+				storage, _ := newStorage(t)
+				admitted(t, storage, "from", limit, limit.Capacity)
+				admitted(t, storage, "to", limit, 1)
+				require.NoError(t, storage.MoveBucket(context.Background(), "from", "to"))
+				assert.Zero(t, admitted(t, storage, "to", limit, limit.Capacity))
+
+				admitted(t, storage, "from", limit, 1)
+				require.NoError(t, storage.MoveBucket(context.Background(), "from", "to"))
+				assert.Zero(t, admitted(t, storage, "to", limit, limit.Capacity))
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "from", limit, limit.Capacity+1))
+			})
+
+			t.Run("ReportsRemaining", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				for i := 1; i < limit.Capacity; i++ {
+					result, err := storage.CheckRateLimit(context.Background(), "key", limit)
+					require.NoError(t, err)
+					assert.Equal(t, bucket.Result{Allowed: true, Remaining: limit.Capacity - i}, result)
+				}
+
+				// The last request empties the bucket, the next one has to wait
+				result, err := storage.CheckRateLimit(context.Background(), "key", limit)
+				require.NoError(t, err)
+				assert.Equal(t, bucket.Result{Allowed: true, RetryAfter: retryAfters[algorithm]}, result)
+			})
+
+			t.Run("ReportsRetryAfter", func(t *testing.T) {
+				storage, fakeClock := newStorage(t)
+				admitted(t, storage, "key", limit, limit.Capacity)
+
+				result, err := storage.CheckRateLimit(context.Background(), "key", limit)
+				require.NoError(t, err)
+				assert.Equal(t, bucket.Result{RetryAfter: retryAfters[algorithm]}, result)
+
+				fakeClock.Advance(result.RetryAfter)
+				assert.Equal(t, 1, admitted(t, storage, "key", limit, 1))
+			})
+
+			t.Run("RecoversGradually", func(t *testing.T) {
+				storage, fakeClock := newStorage(t)
+				admitted(t, storage, "key", limit, limit.Capacity)
+				fakeClock.Advance(limit.Period / time.Duration(limit.Capacity))
+				assert.Equal(t, refills[algorithm], admitted(t, storage, "key", limit, limit.Capacity))
+			})
+
+			t.Run("RecoversFully", func(t *testing.T) {
+				storage, fakeClock := newStorage(t)
+				admitted(t, storage, "key", limit, limit.Capacity)
+				fakeClock.Advance(2 * limit.Period)
+				assert.Equal(t, limit.Capacity, admitted(t, storage, "key", limit, limit.Capacity+1))
+			})
+
+			t.Run("SteadyRate", func(t *testing.T) {
+				storage, fakeClock := newStorage(t)
+				total := 0
+				for i := 0; i < 10*limit.Capacity; i++ {
+					total += admitted(t, storage, "key", limit, 2)
+					fakeClock.Advance(limit.Period / time.Duration(limit.Capacity))
+				}
+				assert.Equal(t, steadyTotals[algorithm], total)
+			})
+
+			t.Run("CheckRateLimitsIsAllOrNothing", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				small := bucket.Limit{Algorithm: algorithm, Capacity: 2, Period: limit.Period}
+				checks := []bucket.Check{{Key: "large", Limit: limit}, {Key: "small", Limit: small}}
+
+				for i := 0; i < small.Capacity; i++ {
+					results, err := storage.CheckRateLimits(context.Background(), checks)
+					require.NoError(t, err)
+					assert.True(t, results[0].Allowed)
+					assert.True(t, results[1].Allowed)
+				}
+
+				results, err := storage.CheckRateLimits(context.Background(), checks)
+				require.NoError(t, err)
+				assert.True(t, results[0].Allowed)
+				assert.False(t, results[1].Allowed)
+
+				// The rejected request was not recorded in the bucket that allowed it
+				assert.Equal(t, limit.Capacity-small.Capacity, admitted(t, storage, "large", limit, limit.Capacity))
+			})
+
+			t.Run("ConcurrentRequests", func(t *testing.T) {
+				storage, _ := newStorage(t)
+				var count atomic.Int64
+				var wg sync.WaitGroup
+				start := make(chan struct{})
+				for i := 0; i < limit.Capacity*10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						<-start
+						result, err := storage.CheckRateLimit(context.Background(), "key", limit)
+						assert.NoError(t, err)
+						if result.Allowed {
+							count.Add(1)
+						}
+					}()
+				}
+				close(start)
+				wg.Wait()
+				assert.Equal(t, int64(limit.Capacity), count.Load())
+			})
+		})
+	}
+
+	t.Run("CheckRateLimitsMixesAlgorithms", func(t *testing.T) {
+		storage := newStorage(t, clock.NewFakeClock(start))
+		var checks []bucket.Check
+		for _, algorithm := range bucket.Algorithms {
+			limit := bucket.Limit{Algorithm: algorithm, Capacity: 3, Period: time.Hour}
+			checks = append(checks, bucket.Check{Key: string(algorithm), Limit: limit})
+		}
+
+		for i := 0; i < 3; i++ {
+			results, err := storage.CheckRateLimits(context.Background(), checks)
+			require.NoError(t, err)
+			require.Len(t, results, len(checks))
+			for j, result := range results {
+				assert.True(t, result.Allowed, checks[j].Key)
+				assert.Equal(t, 2-i, result.Remaining, checks[j].Key)
+			}
+		}
+
+		results, err := storage.CheckRateLimits(context.Background(), checks)
+		require.NoError(t, err)
+		for j, result := range results {
+			assert.False(t, result.Allowed, checks[j].Key)
+		}
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		storage := newStorage(t, clock.NewFakeClock(start))
+		for _, limit := range []bucket.Limit{
+			{Algorithm: "unknown", Capacity: 1, Period: time.Second},
+			{Algorithm: bucket.LeakyBucket, Capacity: 0, Period: time.Second},
+			{Algorithm: bucket.LeakyBucket, Capacity: 1, Period: 0},
+		} {
+			_, err := storage.CheckRateLimit(context.Background(), "key", limit)
+			assert.Error(t, err, limit)
+
+			valid := bucket.Limit{Algorithm: bucket.LeakyBucket, Capacity: 1, Period: time.Second}
+			_, err = storage.CheckRateLimits(context.Background(), []bucket.Check{
+				{Key: "valid", Limit: valid},
+				{Key: "key", Limit: limit},
+			})
+			assert.Error(t, err, limit)
+		}
+	})
+}
+
+func admitted(t *testing.T, storage bucket.Storage, key string, limit bucket.Limit, requests int) int {
+	t.Helper()
+
+	count := 0
+	for i := 0; i < requests; i++ {
+		result, err := storage.CheckRateLimit(context.Background(), key, limit)
+		require.NoError(t, err)
+		if result.Allowed {
+			count++
+		}
+	}
+	return count
+}
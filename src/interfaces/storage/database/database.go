@@ -1,9 +1,121 @@
 package database
 
+import (
+	"context"
+	"time"
+)
+
+// Metadata records why and by whom a network was listed.
+type Metadata struct {
+	Reason    string
+	CreatedBy string
+	// Ticket references the issue or incident the network was listed for
+	Ticket string
+	Labels map[string]string
+}
+
+// Action is what happens to the requests from a listed network.
+type Action string
+
+const (
+	// Allow authorizes the requests without checking any limit.
+	Allow Action = "allow"
+	// Deny rejects the requests.
+	Deny Action = "deny"
+	// Limit checks every limit, as if the network was not listed. It makes
+	// an exception within a network allowed or denied as a whole.
+	Limit Action = "limit"
+	// BypassIPLimit checks the login and password limits, but not the IP one.
+	BypassIPLimit Action = "bypass_ip_limit"
+)
+
+// Actions lists every action.
+var Actions = []Action{Allow, Deny, Limit, BypassIPLimit}
+
+// Rule is how an entry applies to the requests from its network.
+type Rule struct {
+	// Action is the whitelist's Allow or the blacklist's Deny by default
+	Action Action
+	// Priority orders the rules matching a request, the highest one decides.
+	// Among rules of the same priority the precedence of the lists decides
+	Priority int
+}
+
+// Entry is a network on one of the lists.
+type Entry struct {
+	Network string
+	Metadata
+	Rule
+	// Source is the feed the entry was pulled from, empty if it was added by hand
+	Source    string
+	CreatedAt time.Time
+	// ExpiresAt is when the entry stops applying, zero if it never does
+	ExpiresAt time.Time
+}
+
+// ListQuery selects a page of the entries of a list, ordered by creation
+// time and then by network.
+type ListQuery struct {
+	// Contains keeps only the networks holding all of this network, if set
+	Contains string
+	// NewestFirst orders the newest entries first instead of the oldest
+	NewestFirst bool
+	// After is where the previous page ended, nil for the first page
+	After *Cursor
+	Limit int
+}
+
+// Cursor is the position of an entry in a list.
+type Cursor struct {
+	CreatedAt time.Time
+	Network   string
+}
+
+// Page is a page of the entries of a list.
+type Page struct {
+	Entries []Entry
+	// Next is where the page ended, nil if it is the last one
+	Next *Cursor
+}
+
+// ImportResult counts the entries an import changed.
+type ImportResult struct {
+	// Imported counts the entries inserted or updated, not the ones left alone
+	Imported int64
+	// Removed counts the entries deleted because they were not imported
+	Removed int64
+}
+
 type Database interface {
-	Insert(table string, value string) error
+	// Insert adds an entry, replacing an expired entry for the same network.
+	Insert(table string, entry Entry) error
 	Delete(table string, value string) (bool, error)
-	GetAll(table string) ([]string, error)
+	// Import inserts the entries, or updates the ones already there, in
+	// one transaction. Entries pulled from a feed are left to the feed
+	// unless they expired. If replace is set, the entries added by hand that
+	// were not imported are deleted in the same transaction.
+	Import(table string, entries []Entry, replace bool) (ImportResult, error)
+	// GetBySource returns the entries pulled from a feed.
+	GetBySource(table string, source string) ([]Entry, error)
+	// Sync applies the changes of a feed in one transaction: the entries to
+	// add are inserted unless their network is already listed, and the
+	// networks to remove are deleted if they came from the feed.
+	Sync(table string, source string, add []Entry, remove []string) (ImportResult, error)
+	// GetAll returns the entries that have not expired.
+	GetAll(table string) ([]Entry, error)
+	// List returns a page of the entries that have not expired.
+	List(table string, query ListQuery) (Page, error)
+	// GetContaining returns the entries that have not expired whose network
+	// holds the network, shortest prefix first.
+	GetContaining(ctx context.Context, table string, network string) ([]Entry, error)
+	// GetByValue reports whether an entry that has not expired exists.
 	GetByValue(table string, value string) (bool, error)
+	// GetEntry returns the entry for the value if it has not expired, and
+	// whether there is one.
+	GetEntry(table string, value string) (Entry, bool, error)
+	// DeleteExpired deletes the expired entries and returns how many it deleted.
+	DeleteExpired(table string) (int64, error)
+	// Ping reports whether the database is reachable.
+	Ping(ctx context.Context) error
 	Close() error
 }
@@ -1,9 +1,34 @@
 package iplists
 
+import (
+	"context"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+)
+
+// DefaultAction returns the action of the entries of a list that don't set
+// one: the whitelist allows requests and the blacklist denies them.
+func DefaultAction(table string) database.Action {
+	if table == "whitelist" {
+		return database.Allow
+	}
+	return database.Deny
+}
+
+// Repository stores the lists. Networks are masked and entries without an
+// action get the default one of their list.
 type Repository interface {
-	InsertNetwork(table, subnet string) error
+	InsertNetwork(table string, entry database.Entry) error
 	DeleteNetwork(table, subnet string) (bool, error)
-	GetNetworks(table string) ([]string, error)
+	ImportNetworks(table string, entries []database.Entry, replace bool) (database.ImportResult, error)
+	GetNetworks(table string) ([]database.Entry, error)
+	GetSourceNetworks(table, source string) ([]database.Entry, error)
+	SyncNetworks(table, source string, add []database.Entry, remove []string) (database.ImportResult, error)
+	ListNetworks(table string, query database.ListQuery) (database.Page, error)
+	GetContainingNetworks(ctx context.Context, table, network string) ([]database.Entry, error)
 	IsNetworkExists(table, subnet string) (bool, error)
+	GetNetwork(table, subnet string) (database.Entry, bool, error)
+	DeleteExpiredNetworks(table string) (int64, error)
+	Ping(ctx context.Context) error
 	Close() error
 }
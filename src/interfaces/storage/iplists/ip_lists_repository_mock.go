@@ -0,0 +1,80 @@
+package iplists
+
+import (
+	"context"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) InsertNetwork(table string, entry database.Entry) error {
+	args := m.Called(table, entry)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteNetwork(table, subnet string) (bool, error) {
+	args := m.Called(table, subnet)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) ImportNetworks(table string, entries []database.Entry, replace bool) (database.ImportResult, error) {
+	args := m.Called(table, entries, replace)
+	return args.Get(0).(database.ImportResult), args.Error(1)
+}
+
+func (m *MockRepository) GetNetworks(table string) ([]database.Entry, error) {
+	args := m.Called(table)
+	entries, _ := args.Get(0).([]database.Entry)
+	return entries, args.Error(1)
+}
+
+func (m *MockRepository) GetSourceNetworks(table, source string) ([]database.Entry, error) {
+	args := m.Called(table, source)
+	entries, _ := args.Get(0).([]database.Entry)
+	return entries, args.Error(1)
+}
+
+func (m *MockRepository) SyncNetworks(table, source string, add []database.Entry, remove []string) (database.ImportResult, error) {
+	args := m.Called(table, source, add, remove)
+	return args.Get(0).(database.ImportResult), args.Error(1)
+}
+
+func (m *MockRepository) ListNetworks(table string, query database.ListQuery) (database.Page, error) {
+	args := m.Called(table, query)
+	return args.Get(0).(database.Page), args.Error(1)
+}
+
+func (m *MockRepository) GetContainingNetworks(ctx context.Context, table, network string) ([]database.Entry, error) {
+	args := m.Called(ctx, table, network)
+	entries, _ := args.Get(0).([]database.Entry)
+	return entries, args.Error(1)
+}
+
+func (m *MockRepository) IsNetworkExists(table, subnet string) (bool, error) {
+	args := m.Called(table, subnet)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetNetwork(table, subnet string) (database.Entry, bool, error) {
+	args := m.Called(table, subnet)
+	return args.Get(0).(database.Entry), args.Bool(1), args.Error(2)
+}
+
+func (m *MockRepository) DeleteExpiredNetworks(table string) (int64, error) {
+	args := m.Called(table)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
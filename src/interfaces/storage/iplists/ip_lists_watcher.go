@@ -0,0 +1,36 @@
+package iplists
+
+import (
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+)
+
+type ChangeKind int
+
+const (
+	NetworkInserted ChangeKind = iota + 1
+	NetworkDeleted
+	// ChangesLost means changes may have been missed, e.g. while the watcher
+	// was reconnecting, so the lists have to be loaded again.
+	ChangesLost
+)
+
+// Change is a change to one of the lists.
+type Change struct {
+	Kind    ChangeKind
+	Table   string
+	Network string
+	// ExpiresAt is when an inserted network stops applying, zero if it never does
+	ExpiresAt time.Time
+	// Rule is the rule of an inserted network
+	Rule database.Rule
+}
+
+// Watcher delivers the changes made to the lists by any replica.
+type Watcher interface {
+	// Changes returns a channel receiving every change, closed once the
+	// watcher is closed.
+	Changes() <-chan Change
+	Close() error
+}
@@ -0,0 +1,31 @@
+package iplists
+
+import (
+	"sync"
+)
+
+// FakeWatcher is a Watcher delivering the changes it is given.
+type FakeWatcher struct {
+	changes chan Change
+	once    sync.Once
+}
+
+func NewFakeWatcher() *FakeWatcher {
+	return &FakeWatcher{changes: make(chan Change)}
+}
+
+// Send delivers a change, blocking until it is received.
+func (w *FakeWatcher) Send(change Change) {
+	w.changes <- change
+}
+
+func (w *FakeWatcher) Changes() <-chan Change {
+	return w.changes
+}
+
+func (w *FakeWatcher) Close() error {
+	w.once.Do(func() {
+		close(w.changes)
+	})
+	return nil
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the ErrorInfo details of the errors.
+const errorDomain = "ratelimiter"
+
+// listsBackend is the backend the lists are stored in.
+const listsBackend = "postgres"
+
+// Errors are returned as statuses with a code telling the clients what went
+// wrong, and details they can act on:
+//   - InvalidArgument, with the BadRequest violations of the fields
+//   - AlreadyExists and NotFound, with the ResourceInfo of the network
+//   - FailedPrecondition, with the PreconditionFailure violations
+//   - Unavailable, with the ErrorInfo naming the backend that failed
+
+// invalidArgument is the error of a request with an invalid field.
+func invalidArgument(field, description string) error {
+	return badRequest(fmt.Sprintf("invalid %s: %s", field, description),
+		&errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+}
+
+// badRequest is the error of a request with invalid fields.
+func badRequest(message string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	return withDetails(status.New(codes.InvalidArgument, message), &errdetails.BadRequest{FieldViolations: violations})
+}
+
+// alreadyExists is the error of adding a network already on a list.
+func alreadyExists(list, network string) error {
+	return withDetails(status.Newf(codes.AlreadyExists, "%s is already on the %s", network, list),
+		&errdetails.ResourceInfo{ResourceType: list, ResourceName: network})
+}
+
+// notFound is the error of removing a network that is not on a list.
+func notFound(list, network string) error {
+	return withDetails(status.Newf(codes.NotFound, "%s not found in the %s", network, list),
+		&errdetails.ResourceInfo{ResourceType: list, ResourceName: network})
+}
+
+// overlapping is the error of adding a network overlapping listed ones
+// without force, with a violation per overlap.
+func overlapping(network string, overlaps []ipfilter.Overlap) error {
+	violations := make([]*errdetails.PreconditionFailure_Violation, len(overlaps))
+	for i, overlap := range overlaps {
+		violations[i] = &errdetails.PreconditionFailure_Violation{
+			Type:    "OVERLAP",
+			Subject: overlap.Supernet.List + "/" + overlap.Supernet.Network,
+			Description: fmt.Sprintf("%s %s holds %s %s",
+				overlap.Supernet.List, overlap.Supernet.Network, overlap.Subnet.List, overlap.Subnet.Network),
+		}
+	}
+	return withDetails(
+		status.Newf(codes.FailedPrecondition, "%s overlaps %d listed networks, not adding it without force", network, len(overlaps)),
+		&errdetails.PreconditionFailure{Violations: violations})
+}
+
+// unavailable is the error of a call a backend failed. The calls canceled
+// or timed out by the client keep their own code.
+func unavailable(backend string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return withDetails(status.Newf(codes.Unavailable, "%s unavailable: %v", backend, err),
+		&errdetails.ErrorInfo{
+			Reason:   "BACKEND_UNAVAILABLE",
+			Domain:   errorDomain,
+			Metadata: map[string]string{"backend": backend},
+		})
+}
+
+// bucketsUnavailable is the error of a call the bucket storage failed.
+func (s *GrpcServer) bucketsUnavailable(err error) error {
+	backend := s.config.Storage.Backend
+	if backend == "" {
+		backend = "bucket storage"
+	}
+	return unavailable(backend, err)
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// validateIP checks a field holding an IP.
+func validateIP(field, ip string) error {
+	if _, err := netip.ParseAddr(ip); err != nil {
+		return invalidArgument(field, fmt.Sprintf("%q is not an IP", ip))
+	}
+	return nil
+}
+
+// validateNetwork checks a field holding a network in CIDR notation.
+func validateNetwork(field, network string) error {
+	if _, err := netip.ParsePrefix(network); err != nil {
+		return invalidArgument(field, fmt.Sprintf("%q is not a network in CIDR notation", network))
+	}
+	return nil
+}
+
+// validateIPOrNetwork checks a field holding an IP or a network.
+func validateIPOrNetwork(field, value string) error {
+	if _, err := netip.ParseAddr(value); err == nil {
+		return nil
+	}
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return nil
+	}
+	return invalidArgument(field, fmt.Sprintf("%q is neither an IP nor a network in CIDR notation", value))
+}
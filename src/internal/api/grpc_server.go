@@ -2,139 +2,320 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
 	"github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
 	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/metrics"
 	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/internal/bucketkey"
 	"github.com/TheJubadze/RateLimiter/internal/config"
 	"github.com/TheJubadze/RateLimiter/proto/pb"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const tracerName = "github.com/TheJubadze/RateLimiter/internal/api"
+
+var limitExceededMessages = map[pb.LimitType]string{
+	pb.LimitType_LOGIN:    "Login rate limit exceeded",
+	pb.LimitType_PASSWORD: "Password rate limit exceeded",
+	pb.LimitType_IP:       "IP rate limit exceeded",
+}
+
+var limitExceededReasons = map[pb.LimitType]metrics.Reason{
+	pb.LimitType_LOGIN:    metrics.LoginLimit,
+	pb.LimitType_PASSWORD: metrics.PasswordLimit,
+	pb.LimitType_IP:       metrics.IPLimit,
+}
+
 type GrpcServer struct {
 	pb.UnimplementedRateLimiterServer
 	config          *config.Config
 	logger          logger.Logger
+	clock           clock.Clock
 	bucketStorage   bucket.Storage
 	ipFilterService ipfilter.Service
+	recorder        metrics.Recorder
+	keys            *bucketkey.Builder
 }
 
-func NewGrpcServer(cfg *config.Config, logger logger.Logger, bucketStorage bucket.Storage, ipFilterService ipfilter.Service) *GrpcServer {
+// NewGrpcServer creates the server. keys must be the Builder the legacy
+// buckets were migrated with, so both agree on the keys.
+func NewGrpcServer(cfg *config.Config, logger logger.Logger, clock clock.Clock, keys *bucketkey.Builder, bucketStorage bucket.Storage, ipFilterService ipfilter.Service, recorder metrics.Recorder) *GrpcServer {
 	return &GrpcServer{
 		config:          cfg,
 		logger:          logger,
+		clock:           clock,
 		bucketStorage:   bucketStorage,
 		ipFilterService: ipFilterService,
+		recorder:        recorder,
+		keys:            keys,
 	}
 }
 
-// Start starts the gRPC server.
-func (s *GrpcServer) Start() error {
+// Start listens on the configured port and serves until ctx is done, see Serve.
+func (s *GrpcServer) Start(ctx context.Context, register func(server *grpc.Server), opts ...grpc.ServerOption) error {
 	lis, err := net.Listen("tcp", `:`+s.config.GrpcServer.Port)
 	if err != nil {
-		s.logger.Fatalf("Failed to listen: %v", err)
 		return err
 	}
-	grpcServer := grpc.NewServer()
-	pb.RegisterRateLimiterServer(grpcServer, s)
 
 	s.logger.Printf("Starting gRPC server on port %s", s.config.GrpcServer.Port)
-	if err := grpcServer.Serve(lis); err != nil {
-		s.logger.Fatalf("Failed to serve: %v", err)
+	return s.Serve(ctx, lis, register, opts...)
+}
+
+// Serve serves the gRPC server with the given options, e.g. interceptors,
+// on lis until ctx is done. register is called to add other services, once
+// the RateLimiter service is registered.
+// Once ctx is done, the server stops accepting calls and waits for the
+// calls in flight to end. The ones still running after the shutdown
+// timeout are canceled.
+func (s *GrpcServer) Serve(ctx context.Context, lis net.Listener, register func(server *grpc.Server), opts ...grpc.ServerOption) error {
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterRateLimiterServer(grpcServer, s)
+	register(grpcServer)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- grpcServer.Serve(lis)
+	}()
+
+	select {
+	case err := <-served:
 		return err
+	case <-ctx.Done():
 	}
 
-	return nil
+	s.logger.Printf("Stopping gRPC server, waiting for the calls in flight")
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(s.config.GrpcServer.ShutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		s.logger.Printf("Shutdown timeout exceeded, canceling the calls in flight")
+		grpcServer.Stop()
+		<-stopped
+	}
+
+	return <-served
 }
 
 // Authorize implements the Authorize gRPC method.
 func (s *GrpcServer) Authorize(ctx context.Context, req *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
-	s.logger.Printf("Authorize request: %v", req)
-	limitExceededResponse := &pb.AuthorizeResponse{
-		Authorized: false,
+	s.logger.Printf("Authorize request: login: %s, ip: %s", s.loggableLogin(req.Login), req.Ip)
+	if req.Ip != "" {
+		if err := validateIP("ip", req.Ip); err != nil {
+			return nil, err
+		}
 	}
 
-	if s.ipFilterService.IsIPWhitelisted(req.Ip) {
-		return &pb.AuthorizeResponse{
-			Authorized: true,
-			Message:    "Authorized: IP is whitelisted",
-		}, nil
+	// The rule of the IP may skip some or all of the limits
+	checkIP := true
+	decision, ok, err := s.evaluate(ctx, req.Ip)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
 	}
-
-	if s.ipFilterService.IsIPBlacklisted(req.Ip) {
-		return &pb.AuthorizeResponse{
-			Authorized: false,
-			Message:    "Unauthorized: IP is blacklisted",
-		}, nil
+	if ok {
+		switch decision.Action {
+		case database.Allow:
+			reason, message := ruleOutcome(decision)
+			s.decide(ctx, true, reason)
+			return &pb.AuthorizeResponse{
+				Authorized: true,
+				Message:    "Authorized: " + message,
+			}, nil
+		case database.Deny:
+			reason, message := ruleOutcome(decision)
+			s.decide(ctx, false, reason)
+			return &pb.AuthorizeResponse{
+				Authorized: false,
+				Message:    "Unauthorized: " + message,
+				Limit:      pb.LimitType_BLACKLIST,
+			}, nil
+		case database.BypassIPLimit:
+			checkIP = false
+		}
 	}
 
-	leakRate := time.Duration(s.config.LoginLimits.LeakRate) * time.Second
+	limits := s.config.LoginLimits
+	algorithms := s.config.Algorithms
+	var checks []bucket.Check
+	var limitTypes []pb.LimitType
 
 	login := req.GetLogin()
 	if login != "" {
-		success, err := s.bucketStorage.CheckRateLimit(ctx, req.Login, s.config.LoginLimits.Login, leakRate)
-		if err != nil {
-			return nil, err
-		}
-		if !success {
-			limitExceededResponse.Message = "Login rate limit exceeded"
-			return limitExceededResponse, nil
+		key := s.keys.Login(login)
+		if err := s.carryOverBuckets(ctx, s.keys.PreviousLogins(login), key); err != nil {
+			return nil, s.bucketsUnavailable(err)
 		}
+		checks = append(checks, bucket.Check{Key: key, Limit: s.limit(algorithms.Login, limits.Login)})
+		limitTypes = append(limitTypes, pb.LimitType_LOGIN)
 	}
 
 	password := req.GetPassword()
 	if password != "" {
-		success, err := s.bucketStorage.CheckRateLimit(ctx, req.Password, s.config.LoginLimits.Password, leakRate)
-		if err != nil {
-			return nil, err
-		}
-		if !success {
-			limitExceededResponse.Message = "Password rate limit exceeded"
-			return limitExceededResponse, nil
+		key := s.keys.Password(password)
+		if err := s.carryOverBuckets(ctx, s.keys.PreviousPasswords(password), key); err != nil {
+			return nil, s.bucketsUnavailable(err)
 		}
+		checks = append(checks, bucket.Check{Key: key, Limit: s.limit(algorithms.Password, limits.Password)})
+		limitTypes = append(limitTypes, pb.LimitType_PASSWORD)
 	}
 
 	ip := req.GetIp()
-	if ip != "" {
-		success, err := s.bucketStorage.CheckRateLimit(ctx, req.Ip, s.config.LoginLimits.IP, leakRate)
-		if err != nil {
-			return nil, err
-		}
-		if !success {
-			limitExceededResponse.Message = "IP rate limit exceeded"
-			return limitExceededResponse, nil
-		}
+	if ip != "" && checkIP {
+		checks = append(checks, bucket.Check{Key: s.keys.IP(ip), Limit: s.limit(algorithms.IP, limits.IP)})
+		limitTypes = append(limitTypes, pb.LimitType_IP)
 	}
 
-	return &pb.AuthorizeResponse{
+	// A request rejected by one limit must not use up the others, so it is
+	// only recorded if every bucket allows it
+	results, err := s.bucketStorage.CheckRateLimits(ctx, checks)
+	if err != nil {
+		return nil, s.bucketsUnavailable(err)
+	}
+
+	resp := &pb.AuthorizeResponse{
 		Authorized: true,
 		Message:    "Authorized",
-	}, nil
+		Remaining:  &pb.RemainingQuota{},
+	}
+	var retryAfter time.Duration
+	for i, result := range results {
+		remaining := proto.Int32(int32(result.Remaining))
+		switch limitTypes[i] {
+		case pb.LimitType_LOGIN:
+			resp.Remaining.Login = remaining
+		case pb.LimitType_PASSWORD:
+			resp.Remaining.Password = remaining
+		case pb.LimitType_IP:
+			resp.Remaining.Ip = remaining
+		}
+
+		if result.Allowed {
+			continue
+		}
+		// Report the first limit exceeded, and wait until all of them allow the request
+		if resp.Authorized {
+			resp.Authorized = false
+			resp.Limit = limitTypes[i]
+			resp.Message = limitExceededMessages[limitTypes[i]]
+		}
+		retryAfter = max(retryAfter, result.RetryAfter)
+	}
+
+	if !resp.Authorized {
+		resp.RetryAfter = durationpb.New(retryAfter)
+		s.decide(ctx, false, limitExceededReasons[resp.Limit])
+	} else {
+		s.decide(ctx, true, metrics.WithinLimits)
+	}
+
+	return resp, nil
+}
+
+// evaluate finds the rule of the IP in a span of the trace of the call.
+func (s *GrpcServer) evaluate(ctx context.Context, ip string) (ipfilter.Decision, bool, error) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, "ipfilter.Evaluate")
+	defer span.End()
+
+	decision, ok, err := s.ipFilterService.Evaluate(ctx, ip)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		return ipfilter.Decision{}, false, err
+	}
+	span.SetAttributes(attribute.Bool("ratelimiter.ip_list.matched", ok))
+	if ok {
+		span.SetAttributes(
+			attribute.String("ratelimiter.ip_list.list", decision.List),
+			attribute.String("ratelimiter.ip_list.network", decision.Network),
+			attribute.String("ratelimiter.ip_list.action", string(decision.Action)),
+			attribute.Int("ratelimiter.ip_list.priority", decision.Priority),
+		)
+	}
+	return decision, ok, nil
+}
+
+// ruleOutcome returns the reason and the message of a request allowed or
+// denied by the rule of its IP. They depend on the list holding the rule as
+// well as on its action, since either list may hold rules of any action.
+func ruleOutcome(decision ipfilter.Decision) (metrics.Reason, string) {
+	reason := metrics.Whitelist
+	if decision.List == "blacklist" {
+		reason = metrics.Blacklist
+	}
+	switch {
+	case decision.List == "whitelist" && decision.Action == database.Allow:
+		return reason, "IP is whitelisted"
+	case decision.List == "blacklist" && decision.Action == database.Deny:
+		return reason, "IP is blacklisted"
+	case decision.Action == database.Allow:
+		return reason, fmt.Sprintf("IP is allowed by its rule on the %s", decision.List)
+	default:
+		return reason, fmt.Sprintf("IP is denied by its rule on the %s", decision.List)
+	}
+}
+
+// decide records the outcome of an Authorize call, and adds it to the span
+// of the call.
+func (s *GrpcServer) decide(ctx context.Context, authorized bool, reason metrics.Reason) {
+	s.recorder.AuthorizeDecision(authorized, reason)
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Bool("ratelimiter.authorized", authorized),
+		attribute.String("ratelimiter.reason", string(reason)),
+	)
 }
 
 // ResetBucket implements the ResetBucket gRPC method.
 func (s *GrpcServer) ResetBucket(ctx context.Context, req *pb.ResetBucketRequest) (*pb.ResetBucketResponse, error) {
-	if req == nil || (req.Ip == "" && req.Login == "") {
-		return nil, fmt.Errorf("IP or login must be provided")
+	if req.GetIp() == "" && req.GetLogin() == "" {
+		return nil, badRequest("IP or login must be provided",
+			&errdetails.BadRequest_FieldViolation{Field: "ip", Description: "IP or login must be provided"},
+			&errdetails.BadRequest_FieldViolation{Field: "login", Description: "IP or login must be provided"})
 	}
 
 	if req.Ip != "" {
-		err := s.bucketStorage.ResetBucket(ctx, req.Ip)
-		if err != nil {
+		if err := validateIP("ip", req.Ip); err != nil {
 			return nil, err
 		}
+		err := s.bucketStorage.ResetBucket(ctx, s.keys.IP(req.Ip))
+		if err != nil {
+			return nil, s.bucketsUnavailable(err)
+		}
 		s.logger.Printf("Bucket reset for IP: %s", req.Ip)
 	}
 
 	if req.Login != "" {
-		err := s.bucketStorage.ResetBucket(ctx, req.Login)
-		if err != nil {
-			return nil, err
+		keys := append([]string{s.keys.Login(req.Login)}, s.keys.PreviousLogins(req.Login)...)
+		for _, key := range keys {
+			err := s.bucketStorage.ResetBucket(ctx, key)
+			if err != nil {
+				return nil, s.bucketsUnavailable(err)
+			}
 		}
-		s.logger.Printf("Bucket reset for login: %s", req.Login)
+		s.logger.Printf("Bucket reset for login: %s", s.loggableLogin(req.Login))
 	}
 
 	return &pb.ResetBucketResponse{
@@ -146,30 +327,42 @@ func (s *GrpcServer) ResetBucket(ctx context.Context, req *pb.ResetBucketRequest
 func (s *GrpcServer) AddToWhitelist(_ context.Context, req *pb.AddToWhitelistRequest) (*pb.AddToWhitelistResponse, error) {
 	s.logger.Printf("Adding %s to the whitelist", req.Ip)
 
-	isListed, err := isNetworkListed(s, req.Ip)
+	if err := validateNetwork("ip", req.Ip); err != nil {
+		return nil, err
+	}
+	ttl, err := entryTTL(req)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := rule(req)
 	if err != nil {
-		return &pb.AddToWhitelistResponse{
-			Message: err.Error(),
-		}, err
+		return nil, err
 	}
-	if isListed == 1 {
-		return &pb.AddToWhitelistResponse{
-			Message: "IP is already whitelisted",
-		}, nil
+
+	listed, err := s.listOf(req.Ip)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
 	}
-	if isListed == 2 {
-		return &pb.AddToWhitelistResponse{
-			Message: "IP is already blacklisted",
-		}, nil
+	if listed != "" {
+		return nil, alreadyExists(listed, req.Ip)
 	}
 
-	err = s.ipFilterService.AddToWhitelist(req.Ip)
+	overlaps, err := s.ipFilterService.FindOverlaps("whitelist", req.Ip)
 	if err != nil {
-		return nil, err
+		return nil, unavailable(listsBackend, err)
+	}
+	if len(overlaps) > 0 && !req.Force {
+		return nil, overlapping(req.Ip, overlaps)
+	}
+
+	err = s.ipFilterService.AddToWhitelist(req.Ip, ttl, rule, metadata(req))
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
 	}
 
 	return &pb.AddToWhitelistResponse{
-		Message: fmt.Sprintf("Added %s to the whitelist", req.Ip),
+		Message:  fmt.Sprintf("Added %s to the whitelist%s", req.Ip, forTTL(ttl)),
+		Overlaps: overlapsToProto(overlaps),
 	}, nil
 }
 
@@ -177,49 +370,126 @@ func (s *GrpcServer) AddToWhitelist(_ context.Context, req *pb.AddToWhitelistReq
 func (s *GrpcServer) AddToBlacklist(_ context.Context, req *pb.AddToBlacklistRequest) (*pb.AddToBlacklistResponse, error) {
 	s.logger.Printf("Adding %s to the blacklist", req.Ip)
 
-	isListed, err := isNetworkListed(s, req.Ip)
+	if err := validateNetwork("ip", req.Ip); err != nil {
+		return nil, err
+	}
+	ttl, err := entryTTL(req)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := rule(req)
 	if err != nil {
-		return &pb.AddToBlacklistResponse{
-			Message: err.Error(),
-		}, err
+		return nil, err
 	}
-	if isListed == 1 {
-		return &pb.AddToBlacklistResponse{
-			Message: "IP is already whitelisted",
-		}, nil
+
+	listed, err := s.listOf(req.Ip)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
 	}
-	if isListed == 2 {
-		return &pb.AddToBlacklistResponse{
-			Message: "IP is already blacklisted",
-		}, nil
+	if listed != "" {
+		return nil, alreadyExists(listed, req.Ip)
 	}
 
-	err = s.ipFilterService.AddToBlacklist(req.Ip)
+	overlaps, err := s.ipFilterService.FindOverlaps("blacklist", req.Ip)
 	if err != nil {
-		return nil, err
+		return nil, unavailable(listsBackend, err)
+	}
+	if len(overlaps) > 0 && !req.Force {
+		return nil, overlapping(req.Ip, overlaps)
+	}
+
+	err = s.ipFilterService.AddToBlacklist(req.Ip, ttl, rule, metadata(req))
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
 	}
 
 	return &pb.AddToBlacklistResponse{
-		Message: fmt.Sprintf("Added %s to the blacklist", req.Ip),
+		Message:  fmt.Sprintf("Added %s to the blacklist%s", req.Ip, forTTL(ttl)),
+		Overlaps: overlapsToProto(overlaps),
 	}, nil
 }
 
+// InspectNetwork implements the InspectNetwork gRPC method.
+func (s *GrpcServer) InspectNetwork(_ context.Context, req *pb.InspectNetworkRequest) (*pb.InspectNetworkResponse, error) {
+	s.logger.Printf("Inspecting %s", req.Ip)
+
+	if err := validateIPOrNetwork("ip", req.Ip); err != nil {
+		return nil, err
+	}
+	entries, err := s.ipFilterService.Inspect(req.Ip)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
+	}
+
+	resp := &pb.InspectNetworkResponse{}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, listEntry(entry))
+	}
+	return resp, nil
+}
+
+// ListWhitelist implements the ListWhitelist gRPC method.
+func (s *GrpcServer) ListWhitelist(_ context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	return s.list(req, "whitelist", s.ipFilterService.ListWhitelist)
+}
+
+// ListBlacklist implements the ListBlacklist gRPC method.
+func (s *GrpcServer) ListBlacklist(_ context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	return s.list(req, "blacklist", s.ipFilterService.ListBlacklist)
+}
+
+func (s *GrpcServer) list(req *pb.ListRequest, name string, list func(query database.ListQuery) (database.Page, error)) (*pb.ListResponse, error) {
+	s.logger.Printf("Listing the %s", name)
+
+	query, err := listQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := list(query)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
+	}
+
+	resp := &pb.ListResponse{}
+	for _, entry := range page.Entries {
+		resp.Entries = append(resp.Entries, listEntry(ipfilter.Entry{List: name, Entry: entry}))
+	}
+	if page.Next != nil {
+		resp.NextPageToken = encodePageToken(page.Next)
+	}
+	return resp, nil
+}
+
+// CheckListConsistency implements the CheckListConsistency gRPC method.
+func (s *GrpcServer) CheckListConsistency(_ context.Context, _ *pb.CheckListConsistencyRequest) (*pb.CheckListConsistencyResponse, error) {
+	s.logger.Printf("Checking the lists for overlaps")
+
+	overlaps, err := s.ipFilterService.CheckConsistency()
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
+	}
+	return &pb.CheckListConsistencyResponse{Overlaps: overlapsToProto(overlaps)}, nil
+}
+
 // RemoveFromWhitelist implements the RemoveFromWhitelist gRPC method.
 func (s *GrpcServer) RemoveFromWhitelist(_ context.Context, req *pb.RemoveFromWhitelistRequest) (*pb.RemoveFromWhitelistResponse, error) {
 	s.logger.Printf("Removing %s from the whitelist", req.Ip)
 
-	result, err := s.ipFilterService.RemoveFromWhitelist(req.Ip)
-	if err != nil {
+	if err := validateNetwork("ip", req.Ip); err != nil {
 		return nil, err
 	}
 
-	message := fmt.Sprintf("Removed %s from the whitelist", req.Ip)
-	if !result {
-		message = fmt.Sprintf("%s not found in the whitelist", req.Ip)
+	removed, err := s.ipFilterService.RemoveFromWhitelist(req.Ip)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
+	}
+	if !removed {
+		return nil, notFound("whitelist", req.Ip)
 	}
 
 	return &pb.RemoveFromWhitelistResponse{
-		Message: message,
+		Message: fmt.Sprintf("Removed %s from the whitelist", req.Ip),
 	}, nil
 }
 
@@ -227,43 +497,236 @@ func (s *GrpcServer) RemoveFromWhitelist(_ context.Context, req *pb.RemoveFromWh
 func (s *GrpcServer) RemoveFromBlacklist(_ context.Context, req *pb.RemoveFromBlacklistRequest) (*pb.RemoveFromBlacklistResponse, error) {
 	s.logger.Printf("Removing %s from the blacklist", req.Ip)
 
-	result, err := s.ipFilterService.RemoveFromBlacklist(req.Ip)
-	if err != nil {
+	if err := validateNetwork("ip", req.Ip); err != nil {
 		return nil, err
 	}
 
-	message := fmt.Sprintf("Removed %s from the blacklist", req.Ip)
-	if !result {
-		message = fmt.Sprintf("%s not found in the blacklist", req.Ip)
+	removed, err := s.ipFilterService.RemoveFromBlacklist(req.Ip)
+	if err != nil {
+		return nil, unavailable(listsBackend, err)
+	}
+	if !removed {
+		return nil, notFound("blacklist", req.Ip)
 	}
 
 	return &pb.RemoveFromBlacklistResponse{
-		Message: message,
+		Message: fmt.Sprintf("Removed %s from the blacklist", req.Ip),
 	}, nil
 }
 
-// isNetworkListed checks if the IP is already listed in the whitelist or blacklist
-// Returns:
-// -1 - error occurred
-// 0 - IP is not listed
-// 1 - IP is whitelisted
-// 2 - IP is blacklisted.
-func isNetworkListed(s *GrpcServer, ip string) (int, error) {
-	isInList, err := s.ipFilterService.IsNetworkWhitelisted(ip)
+// limit returns the limit of a bucket with the given algorithm and capacity.
+func (s *GrpcServer) limit(algorithm string, capacity int) bucket.Limit {
+	return bucket.Limit{
+		Algorithm: bucket.Algorithm(algorithm),
+		Capacity:  capacity,
+		Period:    s.config.LoginLimits.LeakRate,
+	}
+}
+
+// forTTL describes how long a network is listed for, nothing if for good.
+func forTTL(ttl time.Duration) string {
+	if ttl == 0 {
+		return ""
+	}
+	return " for " + ttl.String()
+}
+
+// carryOverBuckets moves buckets stored under keys derived from previous
+// secrets to the current key while the secret rotation grace period lasts.
+func (s *GrpcServer) carryOverBuckets(ctx context.Context, previousKeys []string, key string) error {
+	for _, previousKey := range previousKeys {
+		if err := s.bucketStorage.MoveBucket(ctx, previousKey, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loggableLogin returns the login as it may appear in logs.
+func (s *GrpcServer) loggableLogin(login string) string {
+	if login != "" && s.keys.HashesLogins() {
+		return "[redacted]"
+	}
+	return login
+}
+
+// listOf returns the list the network is on, empty if it is on neither.
+func (s *GrpcServer) listOf(network string) (string, error) {
+	isInList, err := s.ipFilterService.IsNetworkWhitelisted(network)
 	if err != nil {
-		return -1, err
+		return "", err
 	}
 	if isInList {
-		return 1, nil
+		return "whitelist", nil
 	}
 
-	isInList, err = s.ipFilterService.IsNetworkBlacklisted(ip)
+	isInList, err = s.ipFilterService.IsNetworkBlacklisted(network)
 	if err != nil {
-		return -1, err
+		return "", err
 	}
 	if isInList {
-		return 2, nil
+		return "blacklist", nil
 	}
 
-	return 0, nil
+	return "", nil
+}
+
+// metadataRequest is implemented by the requests adding a network to a list.
+type metadataRequest interface {
+	GetReason() string
+	GetCreatedBy() string
+	GetTicket() string
+	GetLabels() map[string]string
+}
+
+func metadata(req metadataRequest) database.Metadata {
+	return database.Metadata{
+		Reason:    req.GetReason(),
+		CreatedBy: req.GetCreatedBy(),
+		Ticket:    req.GetTicket(),
+		Labels:    req.GetLabels(),
+	}
+}
+
+// ruleRequest is implemented by the requests adding a network to a list.
+type ruleRequest interface {
+	GetAction() pb.RuleAction
+	GetPriority() int32
+}
+
+func rule(req ruleRequest) (database.Rule, error) {
+	r := database.Rule{Priority: int(req.GetPriority())}
+	if req.GetAction() != pb.RuleAction_RULE_ACTION_UNSPECIFIED {
+		for action, value := range ruleActions {
+			if value == req.GetAction() {
+				r.Action = action
+			}
+		}
+		if r.Action == "" {
+			return database.Rule{}, invalidArgument("action", fmt.Sprintf("unknown rule action: %v", req.GetAction()))
+		}
+	}
+	return r, nil
+}
+
+// ttlRequest is implemented by the requests adding a network to a list.
+type ttlRequest interface {
+	GetTtl() *durationpb.Duration
+}
+
+// entryTTL returns how long the network is listed for, zero for good.
+func entryTTL(req ttlRequest) (time.Duration, error) {
+	if req.GetTtl() == nil {
+		return 0, nil
+	}
+	if err := req.GetTtl().CheckValid(); err != nil {
+		return 0, invalidArgument("ttl", err.Error())
+	}
+	ttl := req.GetTtl().AsDuration()
+	if ttl < 0 {
+		return 0, invalidArgument("ttl", fmt.Sprintf("negative duration %s", ttl))
+	}
+	return ttl, nil
+}
+
+var ruleActions = map[database.Action]pb.RuleAction{
+	database.Allow:         pb.RuleAction_RULE_ACTION_ALLOW,
+	database.Deny:          pb.RuleAction_RULE_ACTION_DENY,
+	database.Limit:         pb.RuleAction_RULE_ACTION_LIMIT,
+	database.BypassIPLimit: pb.RuleAction_RULE_ACTION_BYPASS_IP_LIMIT,
+}
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+func listQuery(req *pb.ListRequest) (database.ListQuery, error) {
+	query := database.ListQuery{
+		Contains:    req.GetContains(),
+		NewestFirst: req.GetOrder() == pb.SortOrder_SORT_ORDER_NEWEST_FIRST,
+		Limit:       int(req.GetPageSize()),
+	}
+	if query.Contains != "" {
+		if err := validateIPOrNetwork("contains", query.Contains); err != nil {
+			return database.ListQuery{}, err
+		}
+	}
+	switch {
+	case query.Limit < 0:
+		return database.ListQuery{}, invalidArgument("page_size", fmt.Sprintf("negative page size %d", query.Limit))
+	case query.Limit == 0:
+		query.Limit = defaultPageSize
+	case query.Limit > maxPageSize:
+		query.Limit = maxPageSize
+	}
+
+	if req.GetPageToken() != "" {
+		cursor, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return database.ListQuery{}, err
+		}
+		query.After = cursor
+	}
+	return query, nil
+}
+
+// encodePageToken encodes where a page ended as an opaque token.
+func encodePageToken(cursor *database.Cursor) string {
+	token := fmt.Sprintf("%d,%s", cursor.CreatedAt.UnixMicro(), cursor.Network)
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+func decodePageToken(token string) (*database.Cursor, error) {
+	invalid := invalidArgument("page_token", fmt.Sprintf("%q is not a token of a previous page", token))
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	micros, network, ok := strings.Cut(string(decoded), ",")
+	if !ok {
+		return nil, invalid
+	}
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	return &database.Cursor{CreatedAt: time.UnixMicro(createdAt), Network: network}, nil
+}
+
+var listTypes = map[string]pb.ListType{
+	"whitelist": pb.ListType_LIST_TYPE_WHITELIST,
+	"blacklist": pb.ListType_LIST_TYPE_BLACKLIST,
+}
+
+func listEntry(entry ipfilter.Entry) *pb.ListEntry {
+	listed := &pb.ListEntry{
+		List:      listTypes[entry.List],
+		Network:   entry.Network,
+		Reason:    entry.Reason,
+		CreatedBy: entry.CreatedBy,
+		Ticket:    entry.Ticket,
+		Labels:    entry.Labels,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+		Source:    entry.Source,
+		Action:    ruleActions[entry.Action],
+		Priority:  int32(entry.Priority),
+	}
+	if !entry.ExpiresAt.IsZero() {
+		listed.ExpiresAt = timestamppb.New(entry.ExpiresAt)
+	}
+	return listed
+}
+
+func overlapsToProto(overlaps []ipfilter.Overlap) []*pb.Overlap {
+	var result []*pb.Overlap
+	for _, overlap := range overlaps {
+		result = append(result, &pb.Overlap{
+			SupernetList: listTypes[overlap.Supernet.List],
+			Supernet:     overlap.Supernet.Network,
+			SubnetList:   listTypes[overlap.Subnet.List],
+			Subnet:       overlap.Subnet.Network,
+		})
+	}
+	return result
 }
@@ -2,18 +2,46 @@ package api_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/TheJubadze/RateLimiter/infrastructure/clock"
 	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/infrastructure/storage/memory"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
 	"github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/metrics"
 	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
 	"github.com/TheJubadze/RateLimiter/internal/api"
+	"github.com/TheJubadze/RateLimiter/internal/bucketkey"
 	"github.com/TheJubadze/RateLimiter/internal/config"
 	"github.com/TheJubadze/RateLimiter/proto/pb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// newKeys builds keys from the test secret, with logins in plaintext.
+func newKeys() *bucketkey.Builder {
+	return bucketkey.NewBuilder(systemclock.New(), "test-secret", nil, time.Time{}, 0, false)
+}
+
+func leakyBucket(capacity int) bucket.Limit {
+	return bucket.Limit{Algorithm: bucket.LeakyBucket, Capacity: capacity, Period: time.Second}
+}
+
 func TestAuthorize(t *testing.T) {
 	mockIPFilterService := new(ipfilter.MockIPFilterService)
 	mockBucketStorage := new(bucket.MockBucketStorage)
@@ -22,10 +50,17 @@ func TestAuthorize(t *testing.T) {
 		mockBucketStorage.ExpectedCalls = nil
 	}
 
-	cfg := config.CreateTestConfig(1, 5, 5, 5)
+	cfg := config.CreateTestConfig(time.Second, 5, 5, 5)
 	log := logruslogger.NewLogrusLogger("info")
 
-	server := api.NewGrpcServer(cfg, log, mockBucketStorage, mockIPFilterService)
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), mockBucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	allowed := ipfilter.Decision{List: "whitelist", Network: "192.168.1.0/24", Rule: database.Rule{Action: database.Allow}}
+	denied := ipfilter.Decision{List: "blacklist", Network: "192.168.1.0/24", Rule: database.Rule{Action: database.Deny}}
+	limited := ipfilter.Decision{List: "whitelist", Network: "192.168.1.0/24", Rule: database.Rule{Action: database.Limit}}
+	bypassed := ipfilter.Decision{List: "whitelist", Network: "192.168.1.0/24", Rule: database.Rule{Action: database.BypassIPLimit}}
+	allowedByBlacklist := ipfilter.Decision{List: "blacklist", Network: "192.168.1.0/24", Rule: database.Rule{Action: database.Allow}}
+	deniedByWhitelist := ipfilter.Decision{List: "whitelist", Network: "192.168.1.0/24", Rule: database.Rule{Action: database.Deny}}
 
 	tests := []struct {
 		name       string
@@ -39,7 +74,7 @@ func TestAuthorize(t *testing.T) {
 			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1"},
 			setupMocks: func() {
 				resetMocks()
-				mockIPFilterService.On("IsIPWhitelisted", "192.168.1.1").Return(true)
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(allowed, true, nil)
 			},
 			expected: &pb.AuthorizeResponse{
 				Authorized: true,
@@ -52,12 +87,81 @@ func TestAuthorize(t *testing.T) {
 			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1"},
 			setupMocks: func() {
 				resetMocks()
-				mockIPFilterService.On("IsIPWhitelisted", "192.168.1.1").Return(false)
-				mockIPFilterService.On("IsIPBlacklisted", "192.168.1.1").Return(true)
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(denied, true, nil)
 			},
 			expected: &pb.AuthorizeResponse{
 				Authorized: false,
 				Message:    "Unauthorized: IP is blacklisted",
+				Limit:      pb.LimitType_BLACKLIST,
+			},
+			expectErr: false,
+		},
+		{
+			name: "IP Allowed By Blacklist Rule",
+			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1"},
+			setupMocks: func() {
+				resetMocks()
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(allowedByBlacklist, true, nil)
+			},
+			expected: &pb.AuthorizeResponse{
+				Authorized: true,
+				Message:    "Authorized: IP is allowed by its rule on the blacklist",
+			},
+			expectErr: false,
+		},
+		{
+			name: "IP Denied By Whitelist Rule",
+			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1"},
+			setupMocks: func() {
+				resetMocks()
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(deniedByWhitelist, true, nil)
+			},
+			expected: &pb.AuthorizeResponse{
+				Authorized: false,
+				Message:    "Unauthorized: IP is denied by its rule on the whitelist",
+				Limit:      pb.LimitType_BLACKLIST,
+			},
+			expectErr: false,
+		},
+		{
+			name: "IP Limited By Rule",
+			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user"},
+			setupMocks: func() {
+				resetMocks()
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(limited, true, nil)
+				mockBucketStorage.On("CheckRateLimits", mock.Anything, []bucket.Check{
+					{Key: "rl:login:user", Limit: leakyBucket(5)},
+					{Key: "rl:ip:192.168.1.1", Limit: leakyBucket(5)},
+				}).Return([]bucket.Result{
+					{Allowed: true, Remaining: 4},
+					{Allowed: true, Remaining: 4},
+				}, nil)
+			},
+			expected: &pb.AuthorizeResponse{
+				Authorized: true,
+				Message:    "Authorized",
+				Remaining:  &pb.RemainingQuota{Login: proto.Int32(4), Ip: proto.Int32(4)},
+			},
+			expectErr: false,
+		},
+		{
+			name: "IP Limit Bypassed By Rule",
+			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user"},
+			setupMocks: func() {
+				resetMocks()
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(bypassed, true, nil)
+				mockBucketStorage.On("CheckRateLimits", mock.Anything, []bucket.Check{
+					{Key: "rl:login:user", Limit: leakyBucket(5)},
+				}).Return([]bucket.Result{
+					{RetryAfter: 200 * time.Millisecond},
+				}, nil)
+			},
+			expected: &pb.AuthorizeResponse{
+				Authorized: false,
+				Message:    "Login rate limit exceeded",
+				Limit:      pb.LimitType_LOGIN,
+				Remaining:  &pb.RemainingQuota{Login: proto.Int32(0)},
+				RetryAfter: durationpb.New(200 * time.Millisecond),
 			},
 			expectErr: false,
 		},
@@ -66,13 +170,65 @@ func TestAuthorize(t *testing.T) {
 			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user"},
 			setupMocks: func() {
 				resetMocks()
-				mockIPFilterService.On("IsIPWhitelisted", "192.168.1.1").Return(false)
-				mockIPFilterService.On("IsIPBlacklisted", "192.168.1.1").Return(false)
-				mockBucketStorage.On("CheckRateLimit", mock.Anything, "user", 5, mock.Anything).Return(false, nil)
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+				mockBucketStorage.On("CheckRateLimits", mock.Anything, []bucket.Check{
+					{Key: "rl:login:user", Limit: leakyBucket(5)},
+					{Key: "rl:ip:192.168.1.1", Limit: leakyBucket(5)},
+				}).Return([]bucket.Result{
+					{RetryAfter: 200 * time.Millisecond},
+					{Allowed: true, Remaining: 4},
+				}, nil)
+			},
+			expected: &pb.AuthorizeResponse{
+				Authorized: false,
+				Message:    "Login rate limit exceeded",
+				Limit:      pb.LimitType_LOGIN,
+				Remaining:  &pb.RemainingQuota{Login: proto.Int32(0), Ip: proto.Int32(4)},
+				RetryAfter: durationpb.New(200 * time.Millisecond),
+			},
+			expectErr: false,
+		},
+		{
+			name: "Password Is Not Used As Key",
+			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1", Password: "hunter2"},
+			setupMocks: func() {
+				resetMocks()
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+				hashedKey := mock.MatchedBy(func(checks []bucket.Check) bool {
+					key := checks[0].Key
+					return len(checks) == 2 && strings.HasPrefix(key, "rl:pwd:") && !strings.Contains(key, "hunter2")
+				})
+				mockBucketStorage.On("CheckRateLimits", mock.Anything, hashedKey).Return([]bucket.Result{
+					{RetryAfter: time.Second},
+					{Allowed: true, Remaining: 4},
+				}, nil)
+			},
+			expected: &pb.AuthorizeResponse{
+				Authorized: false,
+				Message:    "Password rate limit exceeded",
+				Limit:      pb.LimitType_PASSWORD,
+				Remaining:  &pb.RemainingQuota{Password: proto.Int32(0), Ip: proto.Int32(4)},
+				RetryAfter: durationpb.New(time.Second),
+			},
+			expectErr: false,
+		},
+		{
+			name: "Waits For Every Exceeded Limit",
+			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user"},
+			setupMocks: func() {
+				resetMocks()
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+				mockBucketStorage.On("CheckRateLimits", mock.Anything, mock.Anything).Return([]bucket.Result{
+					{RetryAfter: time.Second},
+					{RetryAfter: 3 * time.Second},
+				}, nil)
 			},
 			expected: &pb.AuthorizeResponse{
 				Authorized: false,
 				Message:    "Login rate limit exceeded",
+				Limit:      pb.LimitType_LOGIN,
+				Remaining:  &pb.RemainingQuota{Login: proto.Int32(0), Ip: proto.Int32(0)},
+				RetryAfter: durationpb.New(3 * time.Second),
 			},
 			expectErr: false,
 		},
@@ -81,14 +237,19 @@ func TestAuthorize(t *testing.T) {
 			req:  &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user"},
 			setupMocks: func() {
 				resetMocks()
-				mockIPFilterService.On("IsIPWhitelisted", "192.168.1.1").Return(false)
-				mockIPFilterService.On("IsIPBlacklisted", "192.168.1.1").Return(false)
-				mockBucketStorage.On("CheckRateLimit", mock.Anything, "user", 5, mock.Anything).Return(true, nil)
-				mockBucketStorage.On("CheckRateLimit", mock.Anything, "192.168.1.1", 5, mock.Anything).Return(true, nil)
+				mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+				mockBucketStorage.On("CheckRateLimits", mock.Anything, []bucket.Check{
+					{Key: "rl:login:user", Limit: leakyBucket(5)},
+					{Key: "rl:ip:192.168.1.1", Limit: leakyBucket(5)},
+				}).Return([]bucket.Result{
+					{Allowed: true, Remaining: 4},
+					{Allowed: true, Remaining: 2},
+				}, nil)
 			},
 			expected: &pb.AuthorizeResponse{
 				Authorized: true,
 				Message:    "Authorized",
+				Remaining:  &pb.RemainingQuota{Login: proto.Int32(4), Ip: proto.Int32(2)},
 			},
 			expectErr: false,
 		},
@@ -116,7 +277,7 @@ func TestResetBucket(t *testing.T) {
 	log := logruslogger.NewLogrusLogger("info")
 	mockIPFilterService := new(ipfilter.MockIPFilterService)
 
-	server := api.NewGrpcServer(cfg, log, mockBucketStorage, mockIPFilterService)
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), mockBucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
 
 	tests := []struct {
 		name       string
@@ -129,7 +290,7 @@ func TestResetBucket(t *testing.T) {
 			name: "Reset IP Bucket",
 			req:  &pb.ResetBucketRequest{Ip: "192.168.1.1"},
 			setupMocks: func() {
-				mockBucketStorage.On("ResetBucket", mock.Anything, "192.168.1.1").Return(nil)
+				mockBucketStorage.On("ResetBucket", mock.Anything, "rl:ip:192.168.1.1").Return(nil)
 			},
 			expected: &pb.ResetBucketResponse{
 				Message: "Bucket reset",
@@ -140,7 +301,7 @@ func TestResetBucket(t *testing.T) {
 			name: "Reset Login Bucket",
 			req:  &pb.ResetBucketRequest{Login: "user"},
 			setupMocks: func() {
-				mockBucketStorage.On("ResetBucket", mock.Anything, "user").Return(nil)
+				mockBucketStorage.On("ResetBucket", mock.Anything, "rl:login:user").Return(nil)
 			},
 			expected: &pb.ResetBucketResponse{
 				Message: "Bucket reset",
@@ -175,13 +336,14 @@ func TestAddToWhitelist(t *testing.T) {
 	mockIPFilterService := new(ipfilter.MockIPFilterService)
 	mockIPFilterService.On("IsNetworkWhitelisted", "192.168.1.1/24").Return(false, nil)
 	mockIPFilterService.On("IsNetworkBlacklisted", "192.168.1.1/24").Return(false, nil)
-	mockIPFilterService.On("AddToWhitelist", "192.168.1.1/24").Return(nil)
+	mockIPFilterService.On("FindOverlaps", "whitelist", "192.168.1.1/24").Return([]ipfilter.Overlap(nil), nil)
+	mockIPFilterService.On("AddToWhitelist", "192.168.1.1/24", time.Duration(0), database.Rule{}, database.Metadata{}).Return(nil)
 
 	cfg := &config.Config{}
 	log := logruslogger.NewLogrusLogger("info")
 	bucketStorage := new(bucket.MockBucketStorage)
 
-	server := api.NewGrpcServer(cfg, log, bucketStorage, mockIPFilterService)
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
 
 	req := &pb.AddToWhitelistRequest{Ip: "192.168.1.1/24"}
 	resp, err := server.AddToWhitelist(context.Background(), req)
@@ -195,22 +357,217 @@ func TestAddToBlacklist(t *testing.T) {
 	mockIPFilterService := new(ipfilter.MockIPFilterService)
 	mockIPFilterService.On("IsNetworkWhitelisted", "192.168.1.1/24").Return(false, nil)
 	mockIPFilterService.On("IsNetworkBlacklisted", "192.168.1.1/24").Return(false, nil)
-	mockIPFilterService.On("AddToBlacklist", "192.168.1.1/24").Return(nil)
+	mockIPFilterService.On("FindOverlaps", "blacklist", "192.168.1.1/24").Return([]ipfilter.Overlap(nil), nil)
+	metadata := database.Metadata{Reason: "abuse", CreatedBy: "alice", Ticket: "SEC-1", Labels: map[string]string{"source": "manual"}}
+	mockIPFilterService.On("AddToBlacklist", "192.168.1.1/24", 30*time.Minute, database.Rule{Action: database.Limit, Priority: 10}, metadata).Return(nil)
 
 	cfg := &config.Config{}
 	log := logruslogger.NewLogrusLogger("info")
 	bucketStorage := new(bucket.MockBucketStorage)
 
-	server := api.NewGrpcServer(cfg, log, bucketStorage, mockIPFilterService)
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
 
-	req := &pb.AddToBlacklistRequest{Ip: "192.168.1.1/24"}
+	req := &pb.AddToBlacklistRequest{
+		Ip:        "192.168.1.1/24",
+		Ttl:       durationpb.New(30 * time.Minute),
+		Reason:    "abuse",
+		CreatedBy: "alice",
+		Ticket:    "SEC-1",
+		Labels:    map[string]string{"source": "manual"},
+		Action:    pb.RuleAction_RULE_ACTION_LIMIT,
+		Priority:  10,
+	}
 	resp, err := server.AddToBlacklist(context.Background(), req)
 
 	assert.NoError(t, err)
-	assert.Equal(t, "Added 192.168.1.1/24 to the blacklist", resp.Message)
+	assert.Equal(t, "Added 192.168.1.1/24 to the blacklist for 30m0s", resp.Message)
+	mockIPFilterService.AssertExpectations(t)
+}
+
+func TestAddOverlappingNetwork(t *testing.T) {
+	overlaps := []ipfilter.Overlap{
+		{
+			Supernet: ipfilter.ListedNetwork{List: "whitelist", Network: "10.0.0.0/8"},
+			Subnet:   ipfilter.ListedNetwork{List: "blacklist", Network: "10.1.0.0/16"},
+		},
+	}
+	expected := []*pb.Overlap{
+		{
+			SupernetList: pb.ListType_LIST_TYPE_WHITELIST,
+			Supernet:     "10.0.0.0/8",
+			SubnetList:   pb.ListType_LIST_TYPE_BLACKLIST,
+			Subnet:       "10.1.0.0/16",
+		},
+	}
+
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("IsNetworkWhitelisted", "10.1.0.0/16").Return(false, nil)
+	mockIPFilterService.On("IsNetworkBlacklisted", "10.1.0.0/16").Return(false, nil)
+	mockIPFilterService.On("FindOverlaps", "blacklist", "10.1.0.0/16").Return(overlaps, nil)
+
+	cfg := &config.Config{}
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := new(bucket.MockBucketStorage)
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	resp, err := server.AddToBlacklist(context.Background(), &pb.AddToBlacklistRequest{Ip: "10.1.0.0/16"})
+
+	assert.Nil(t, resp)
+	st := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Equal(t, "10.1.0.0/16 overlaps 1 listed networks, not adding it without force", st.Message())
+	if assert.Len(t, st.Details(), 1) {
+		assert.True(t, proto.Equal(&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        "OVERLAP",
+			Subject:     "whitelist/10.0.0.0/8",
+			Description: "whitelist 10.0.0.0/8 holds blacklist 10.1.0.0/16",
+		}}}, st.Details()[0].(proto.Message)), st.Details())
+	}
+	mockIPFilterService.AssertNotCalled(t, "AddToBlacklist", mock.Anything, mock.Anything, mock.Anything)
+
+	mockIPFilterService.On("AddToBlacklist", "10.1.0.0/16", time.Duration(0), database.Rule{}, database.Metadata{}).Return(nil)
+
+	resp, err = server.AddToBlacklist(context.Background(), &pb.AddToBlacklistRequest{Ip: "10.1.0.0/16", Force: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Added 10.1.0.0/16 to the blacklist", resp.Message)
+	if assert.Len(t, resp.Overlaps, 1) {
+		assert.True(t, proto.Equal(expected[0], resp.Overlaps[0]), resp.Overlaps)
+	}
+	mockIPFilterService.AssertExpectations(t)
+}
+
+func TestCheckListConsistency(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("CheckConsistency").Return([]ipfilter.Overlap{
+		{
+			Supernet: ipfilter.ListedNetwork{List: "whitelist", Network: "172.16.0.0/12"},
+			Subnet:   ipfilter.ListedNetwork{List: "blacklist", Network: "172.16.0.0/12"},
+		},
+	}, nil)
+
+	cfg := &config.Config{}
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := new(bucket.MockBucketStorage)
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	resp, err := server.CheckListConsistency(context.Background(), &pb.CheckListConsistencyRequest{})
+
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&pb.CheckListConsistencyResponse{Overlaps: []*pb.Overlap{
+		{
+			SupernetList: pb.ListType_LIST_TYPE_WHITELIST,
+			Supernet:     "172.16.0.0/12",
+			SubnetList:   pb.ListType_LIST_TYPE_BLACKLIST,
+			Subnet:       "172.16.0.0/12",
+		},
+	}}, resp), resp)
+	mockIPFilterService.AssertExpectations(t)
+}
+
+func TestInspectNetwork(t *testing.T) {
+	createdAt := time.Unix(1700000000, 0)
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Inspect", "10.1.2.3").Return([]ipfilter.Entry{
+		{List: "whitelist", Entry: database.Entry{Network: "10.1.0.0/16", CreatedAt: createdAt}},
+		{List: "blacklist", Entry: database.Entry{
+			Network:   "10.0.0.0/8",
+			Metadata:  database.Metadata{Reason: "abuse", CreatedBy: "alice", Ticket: "SEC-1", Labels: map[string]string{"source": "manual"}},
+			CreatedAt: createdAt,
+			ExpiresAt: createdAt.Add(time.Hour),
+		}},
+	}, nil)
+
+	cfg := &config.Config{}
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := new(bucket.MockBucketStorage)
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	resp, err := server.InspectNetwork(context.Background(), &pb.InspectNetworkRequest{Ip: "10.1.2.3"})
+
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&pb.InspectNetworkResponse{Entries: []*pb.ListEntry{
+		{List: pb.ListType_LIST_TYPE_WHITELIST, Network: "10.1.0.0/16", CreatedAt: timestamppb.New(createdAt)},
+		{
+			List:      pb.ListType_LIST_TYPE_BLACKLIST,
+			Network:   "10.0.0.0/8",
+			Reason:    "abuse",
+			CreatedBy: "alice",
+			Ticket:    "SEC-1",
+			Labels:    map[string]string{"source": "manual"},
+			CreatedAt: timestamppb.New(createdAt),
+			ExpiresAt: timestamppb.New(createdAt.Add(time.Hour)),
+		},
+	}}, resp), resp)
+	mockIPFilterService.AssertExpectations(t)
+}
+
+func TestListWhitelist(t *testing.T) {
+	createdAt := time.Unix(1700000000, 0)
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("ListWhitelist", database.ListQuery{Contains: "10.1.2.3", NewestFirst: true, Limit: 100}).Return(database.Page{
+		Entries: []database.Entry{{Network: "10.0.0.0/8", Metadata: database.Metadata{Reason: "office"}, CreatedAt: createdAt}},
+		Next:    &database.Cursor{CreatedAt: createdAt, Network: "10.0.0.0/8"},
+	}, nil).Once()
+
+	cfg := &config.Config{}
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := new(bucket.MockBucketStorage)
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	req := &pb.ListRequest{Contains: "10.1.2.3", Order: pb.SortOrder_SORT_ORDER_NEWEST_FIRST}
+	resp, err := server.ListWhitelist(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&pb.ListEntry{
+		List:      pb.ListType_LIST_TYPE_WHITELIST,
+		Network:   "10.0.0.0/8",
+		Reason:    "office",
+		CreatedAt: timestamppb.New(createdAt),
+	}, resp.Entries[0]), resp)
+	assert.NotEmpty(t, resp.NextPageToken)
+
+	// The token picks up where the page ended
+	mockIPFilterService.On("ListWhitelist", database.ListQuery{
+		Contains:    "10.1.2.3",
+		NewestFirst: true,
+		After:       &database.Cursor{CreatedAt: createdAt, Network: "10.0.0.0/8"},
+		Limit:       1000,
+	}).Return(database.Page{}, nil).Once()
+
+	req.PageToken = resp.NextPageToken
+	req.PageSize = 5000
+	resp, err = server.ListWhitelist(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Entries)
+	assert.Empty(t, resp.NextPageToken)
 	mockIPFilterService.AssertExpectations(t)
 }
 
+func TestListRejectsInvalidRequests(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+
+	cfg := &config.Config{}
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := new(bucket.MockBucketStorage)
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	for _, req := range []*pb.ListRequest{
+		{PageSize: -1},
+		{PageToken: "not a token"},
+		{PageToken: "bm90IGEgdG9rZW4"},
+		{Contains: "10.0.0"},
+	} {
+		_, err := server.ListBlacklist(context.Background(), req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req)
+	}
+	mockIPFilterService.AssertNotCalled(t, "ListBlacklist", mock.Anything)
+}
+
 func TestRemoveFromWhitelist(t *testing.T) {
 	mockIPFilterService := new(ipfilter.MockIPFilterService)
 	mockIPFilterService.On("RemoveFromWhitelist", "192.168.1.1/24").Return(true, nil)
@@ -219,7 +576,7 @@ func TestRemoveFromWhitelist(t *testing.T) {
 	log := logruslogger.NewLogrusLogger("info")
 	bucketStorage := new(bucket.MockBucketStorage)
 
-	server := api.NewGrpcServer(cfg, log, bucketStorage, mockIPFilterService)
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
 
 	req := &pb.RemoveFromWhitelistRequest{Ip: "192.168.1.1/24"}
 	resp, err := server.RemoveFromWhitelist(context.Background(), req)
@@ -237,7 +594,7 @@ func TestRemoveFromBlacklist(t *testing.T) {
 	log := logruslogger.NewLogrusLogger("info")
 	bucketStorage := new(bucket.MockBucketStorage)
 
-	server := api.NewGrpcServer(cfg, log, bucketStorage, mockIPFilterService)
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
 
 	req := &pb.RemoveFromBlacklistRequest{Ip: "192.168.1.1/24"}
 	resp, err := server.RemoveFromBlacklist(context.Background(), req)
@@ -246,3 +603,302 @@ func TestRemoveFromBlacklist(t *testing.T) {
 	assert.Equal(t, "Removed 192.168.1.1/24 from the blacklist", resp.Message)
 	mockIPFilterService.AssertExpectations(t)
 }
+
+func TestHandlersReturnStatusCodes(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("IsNetworkWhitelisted", "10.0.0.0/8").Return(false, nil)
+	mockIPFilterService.On("IsNetworkBlacklisted", "10.0.0.0/8").Return(true, nil)
+	mockIPFilterService.On("RemoveFromWhitelist", "10.0.0.0/8").Return(false, nil)
+	mockIPFilterService.On("Inspect", "10.0.0.1").Return([]ipfilter.Entry(nil), errors.New("connection refused"))
+	mockIPFilterService.On("Evaluate", mock.Anything, "10.0.0.2").Return(ipfilter.Decision{}, false, errors.New("connection refused"))
+	mockBucketStorage := new(bucket.MockBucketStorage)
+	mockBucketStorage.On("ResetBucket", mock.Anything, "rl:ip:10.0.0.1").Return(errors.New("connection refused"))
+
+	cfg := &config.Config{}
+	cfg.Storage.Backend = "redis"
+	log := logruslogger.NewLogrusLogger("info")
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), mockBucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func() error
+		code    codes.Code
+		details proto.Message
+	}{
+		{
+			name: "invalid IP",
+			call: func() error {
+				_, err := server.Authorize(ctx, &pb.AuthorizeRequest{Login: "user", Ip: "10.0.0"})
+				return err
+			},
+			code: codes.InvalidArgument,
+			details: &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "ip", Description: `"10.0.0" is not an IP`},
+			}},
+		},
+		{
+			name: "negative TTL",
+			call: func() error {
+				_, err := server.AddToWhitelist(ctx, &pb.AddToWhitelistRequest{Ip: "10.0.0.0/8", Ttl: durationpb.New(-time.Minute)})
+				return err
+			},
+			code: codes.InvalidArgument,
+			details: &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "ttl", Description: "negative duration -1m0s"},
+			}},
+		},
+		{
+			name: "already listed",
+			call: func() error {
+				_, err := server.AddToWhitelist(ctx, &pb.AddToWhitelistRequest{Ip: "10.0.0.0/8"})
+				return err
+			},
+			code:    codes.AlreadyExists,
+			details: &errdetails.ResourceInfo{ResourceType: "blacklist", ResourceName: "10.0.0.0/8"},
+		},
+		{
+			name: "not listed",
+			call: func() error {
+				_, err := server.RemoveFromWhitelist(ctx, &pb.RemoveFromWhitelistRequest{Ip: "10.0.0.0/8"})
+				return err
+			},
+			code:    codes.NotFound,
+			details: &errdetails.ResourceInfo{ResourceType: "whitelist", ResourceName: "10.0.0.0/8"},
+		},
+		{
+			name: "lists unavailable",
+			call: func() error {
+				_, err := server.InspectNetwork(ctx, &pb.InspectNetworkRequest{Ip: "10.0.0.1"})
+				return err
+			},
+			code: codes.Unavailable,
+			details: &errdetails.ErrorInfo{
+				Reason:   "BACKEND_UNAVAILABLE",
+				Domain:   "ratelimiter",
+				Metadata: map[string]string{"backend": "postgres"},
+			},
+		},
+		{
+			name: "lists unavailable to authorize",
+			call: func() error {
+				_, err := server.Authorize(ctx, &pb.AuthorizeRequest{Login: "user", Ip: "10.0.0.2"})
+				return err
+			},
+			code: codes.Unavailable,
+			details: &errdetails.ErrorInfo{
+				Reason:   "BACKEND_UNAVAILABLE",
+				Domain:   "ratelimiter",
+				Metadata: map[string]string{"backend": "postgres"},
+			},
+		},
+		{
+			name: "buckets unavailable",
+			call: func() error {
+				_, err := server.ResetBucket(ctx, &pb.ResetBucketRequest{Ip: "10.0.0.1"})
+				return err
+			},
+			code: codes.Unavailable,
+			details: &errdetails.ErrorInfo{
+				Reason:   "BACKEND_UNAVAILABLE",
+				Domain:   "ratelimiter",
+				Metadata: map[string]string{"backend": "redis"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(tt.call())
+			assert.Equal(t, tt.code, st.Code(), st.Message())
+			if assert.Len(t, st.Details(), 1) {
+				assert.True(t, proto.Equal(tt.details, st.Details()[0].(proto.Message)), st.Details())
+			}
+		})
+	}
+	mockIPFilterService.AssertNotCalled(t, "AddToWhitelist", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockBucketStorage.AssertNotCalled(t, "CheckRateLimits", mock.Anything, mock.Anything)
+}
+
+func TestAuthorizeWithMemoryStorage(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+
+	cfg := config.CreateTestConfig(time.Minute, 2, 5, 5)
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := memorystorage.NewMemoryBucketStorage(log, systemclock.New(), 0)
+	defer bucketStorage.Close()
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	req := &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user", Password: "secret"}
+	for i := 0; i < 2; i++ {
+		resp, err := server.Authorize(context.Background(), req)
+		assert.NoError(t, err)
+		assert.True(t, resp.Authorized)
+	}
+
+	resp, err := server.Authorize(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, resp.Authorized)
+	assert.Equal(t, pb.LimitType_LOGIN, resp.Limit)
+	assert.Equal(t, "Login rate limit exceeded", resp.Message)
+
+	_, err = server.ResetBucket(context.Background(), &pb.ResetBucketRequest{Login: "user"})
+	assert.NoError(t, err)
+
+	resp, err = server.Authorize(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, resp.Authorized)
+}
+
+func TestAuthorizeCarriesOverBucketsOfPreviousSecret(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+
+	cfg := config.CreateTestConfig(time.Minute, 5, 2, 5)
+	log := logruslogger.NewLogrusLogger("info")
+	fakeClock := clock.NewFakeClock(time.Unix(1700000000, 0))
+	bucketStorage := memorystorage.NewMemoryBucketStorage(log, fakeClock, 0)
+	defer bucketStorage.Close()
+
+	req := &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user", Password: "secret"}
+	before := api.NewGrpcServer(cfg, log, fakeClock, bucketkey.NewBuilder(fakeClock, "old", nil, time.Time{}, 0, true),
+		bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+	for i := 0; i < 2; i++ {
+		resp, err := before.Authorize(context.Background(), req)
+		assert.NoError(t, err)
+		assert.True(t, resp.Authorized)
+	}
+
+	// The password bucket filled under the previous secret still applies
+	rotated := bucketkey.NewBuilder(fakeClock, "new", []string{"old"}, fakeClock.Now(), time.Hour, true)
+	after := api.NewGrpcServer(cfg, log, fakeClock, rotated, bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+	resp, err := after.Authorize(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, resp.Authorized)
+	assert.Equal(t, pb.LimitType_PASSWORD, resp.Limit)
+}
+
+func TestAuthorizeRejectedRequestUsesNoQuota(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Evaluate", mock.Anything, mock.Anything).Return(ipfilter.Decision{}, false, nil)
+
+	cfg := config.CreateTestConfig(time.Minute, 5, 5, 2)
+	log := logruslogger.NewLogrusLogger("panic")
+	bucketStorage := memorystorage.NewMemoryBucketStorage(log, systemclock.New(), 0)
+	defer bucketStorage.Close()
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	// An attacker exhausts the limit of their IP guessing the user's password
+	attack := &pb.AuthorizeRequest{Ip: "10.0.0.1", Login: "user", Password: "guess"}
+	for i := 0; i < 10; i++ {
+		_, err := server.Authorize(context.Background(), attack)
+		assert.NoError(t, err)
+	}
+
+	// Only the attempts that got through count against the login
+	resp, err := server.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user", Password: "secret"})
+	assert.NoError(t, err)
+	assert.True(t, resp.Authorized)
+	assert.Equal(t, int32(2), resp.Remaining.GetLogin())
+}
+
+func TestAuthorizeSimulatedTraffic(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Evaluate", mock.Anything, "192.168.1.1").Return(ipfilter.Decision{}, false, nil)
+
+	// 10 login attempts a minute, one leaks every 6 seconds
+	cfg := config.CreateTestConfig(time.Minute, 10, 1000, 1000)
+	log := logruslogger.NewLogrusLogger("panic")
+	fakeClock := clock.NewFakeClock(time.Unix(1700000000, 0))
+	bucketStorage := memorystorage.NewMemoryBucketStorage(log, fakeClock, 0)
+	defer bucketStorage.Close()
+
+	server := api.NewGrpcServer(cfg, log, fakeClock, newKeys(), bucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+	req := &pb.AuthorizeRequest{Ip: "192.168.1.1", Login: "user"}
+	authorized := func() bool {
+		resp, err := server.Authorize(context.Background(), req)
+		assert.NoError(t, err)
+		return resp.Authorized
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, authorized())
+	}
+	resp, err := server.Authorize(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, resp.Authorized)
+	assert.Equal(t, 6*time.Second, resp.RetryAfter.AsDuration())
+	assert.Equal(t, int32(0), resp.Remaining.GetLogin())
+
+	// Ten simulated minutes: every leaked attempt is available again, and only that one
+	for i := 0; i < 100; i++ {
+		fakeClock.Advance(6 * time.Second)
+		assert.True(t, authorized())
+		assert.False(t, authorized())
+	}
+}
+
+func TestAuthorizeRecordsDecisions(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Evaluate", mock.Anything, "10.0.0.1").Return(ipfilter.Decision{List: "whitelist", Rule: database.Rule{Action: database.Allow}}, true, nil)
+	mockIPFilterService.On("Evaluate", mock.Anything, "10.0.0.2").Return(ipfilter.Decision{List: "blacklist", Rule: database.Rule{Action: database.Deny}}, true, nil)
+	mockIPFilterService.On("Evaluate", mock.Anything, "10.0.0.3").Return(ipfilter.Decision{}, false, nil)
+	mockIPFilterService.On("Evaluate", mock.Anything, "10.0.0.4").Return(ipfilter.Decision{List: "blacklist", Rule: database.Rule{Action: database.Allow}}, true, nil)
+
+	cfg := config.CreateTestConfig(time.Minute, 1, 5, 5)
+	log := logruslogger.NewLogrusLogger("info")
+	bucketStorage := memorystorage.NewMemoryBucketStorage(log, systemclock.New(), 0)
+	recorder := &metrics.FakeRecorder{}
+
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), bucketStorage, mockIPFilterService, recorder)
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.3", "10.0.0.4"} {
+		_, err := server.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: ip, Login: "user"})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []metrics.Decision{
+		{Authorized: true, Reason: metrics.Whitelist},
+		{Authorized: false, Reason: metrics.Blacklist},
+		{Authorized: true, Reason: metrics.WithinLimits},
+		{Authorized: false, Reason: metrics.LoginLimit},
+		{Authorized: true, Reason: metrics.Blacklist},
+	}, recorder.Decisions())
+}
+
+func TestAuthorizeTracesDecision(t *testing.T) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	decision := ipfilter.Decision{List: "blacklist", Network: "10.0.0.0/8", Rule: database.Rule{Action: database.Deny, Priority: 5}}
+	// The lists are read in the span of the evaluation, so the queries are part of the trace
+	var evaluateCtx context.Context
+	mockIPFilterService.On("Evaluate", mock.Anything, "10.0.0.1").Return(decision, true, nil).Run(func(args mock.Arguments) {
+		evaluateCtx = args.Get(0).(context.Context)
+	})
+
+	cfg := config.CreateTestConfig(time.Minute, 5, 5, 5)
+	log := logruslogger.NewLogrusLogger("info")
+	server := api.NewGrpcServer(cfg, log, systemclock.New(), newKeys(), new(bucket.MockBucketStorage), mockIPFilterService, &metrics.FakeRecorder{})
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "Authorize")
+	_, err := server.Authorize(ctx, &pb.AuthorizeRequest{Ip: "10.0.0.1"})
+	span.End()
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	evaluate, call := spans[0], spans[1]
+	assert.Equal(t, "ipfilter.Evaluate", evaluate.Name)
+	assert.Equal(t, call.SpanContext.SpanID(), evaluate.Parent.SpanID())
+	assert.Equal(t, evaluate.SpanContext.SpanID(), trace.SpanFromContext(evaluateCtx).SpanContext().SpanID())
+	assert.Contains(t, evaluate.Attributes, attribute.String("ratelimiter.ip_list.list", "blacklist"))
+	assert.Contains(t, evaluate.Attributes, attribute.String("ratelimiter.ip_list.network", "10.0.0.0/8"))
+	assert.Contains(t, evaluate.Attributes, attribute.String("ratelimiter.ip_list.action", "deny"))
+	assert.Contains(t, call.Attributes, attribute.Bool("ratelimiter.authorized", false))
+	assert.Contains(t, call.Attributes, attribute.String("ratelimiter.reason", "blacklist"))
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/internal/listfile"
+	"github.com/TheJubadze/RateLimiter/proto/pb"
+	"google.golang.org/grpc"
+)
+
+var listFormats = map[pb.ListFormat]listfile.Format{
+	pb.ListFormat_LIST_FORMAT_TEXT: listfile.Text,
+	pb.ListFormat_LIST_FORMAT_CSV:  listfile.CSV,
+	pb.ListFormat_LIST_FORMAT_JSON: listfile.JSON,
+}
+
+// ImportList implements the ImportList gRPC method. The whole file is read
+// before anything is imported, so a file with invalid lines changes nothing.
+func (s *GrpcServer) ImportList(stream grpc.ClientStreamingServer[pb.ImportListRequest, pb.ImportListResponse]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	var importList func(entries []database.Entry, replace bool) (database.ImportResult, error)
+	switch first.GetList() {
+	case pb.ListType_LIST_TYPE_WHITELIST:
+		importList = s.ipFilterService.ImportWhitelist
+	case pb.ListType_LIST_TYPE_BLACKLIST:
+		importList = s.ipFilterService.ImportBlacklist
+	default:
+		return invalidArgument("list", fmt.Sprintf("unknown list %s", first.GetList()))
+	}
+	format, ok := listFormats[first.GetFormat()]
+	if !ok {
+		return invalidArgument("format", fmt.Sprintf("unknown list format %s", first.GetFormat()))
+	}
+	replace := first.GetMode() == pb.ImportMode_IMPORT_MODE_REPLACE
+	s.logger.Printf("Importing the %s, replace: %t", first.GetList(), replace)
+
+	now := s.clock.Now()
+	resp := &pb.ImportListResponse{}
+	var entries []database.Entry
+	r := &importReader{stream: stream, data: first.GetData()}
+	err = listfile.Read(r, format, func(line int, entry database.Entry, err error) error {
+		if err == nil && !entry.ExpiresAt.IsZero() && !entry.ExpiresAt.After(now) {
+			err = errors.New("already expired")
+		}
+		if err != nil {
+			resp.Errors = append(resp.Errors, &pb.LineError{Line: int32(line), Message: err.Error()})
+			return nil
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		s.logger.Printf("Import rejected, %d invalid lines", len(resp.Errors))
+		return stream.SendAndClose(resp)
+	}
+
+	result, err := importList(entries, replace)
+	if err != nil {
+		return unavailable(listsBackend, err)
+	}
+	s.logger.Printf("Imported %d networks, removed %d", result.Imported, result.Removed)
+
+	resp.Imported = int32(result.Imported)
+	resp.Removed = int32(result.Removed)
+	return stream.SendAndClose(resp)
+}
+
+// ExportList implements the ExportList gRPC method.
+func (s *GrpcServer) ExportList(req *pb.ExportListRequest, stream grpc.ServerStreamingServer[pb.ExportListResponse]) error {
+	var list func(query database.ListQuery) (database.Page, error)
+	switch req.GetList() {
+	case pb.ListType_LIST_TYPE_WHITELIST:
+		list = s.ipFilterService.ListWhitelist
+	case pb.ListType_LIST_TYPE_BLACKLIST:
+		list = s.ipFilterService.ListBlacklist
+	default:
+		return invalidArgument("list", fmt.Sprintf("unknown list %s", req.GetList()))
+	}
+	format, ok := listFormats[req.GetFormat()]
+	if !ok {
+		return invalidArgument("format", fmt.Sprintf("unknown list format %s", req.GetFormat()))
+	}
+	s.logger.Printf("Exporting the %s", req.GetList())
+
+	w := listfile.NewWriter(exportWriter{stream: stream}, format)
+	query := database.ListQuery{Limit: maxPageSize}
+	for {
+		page, err := list(query)
+		if err != nil {
+			return unavailable(listsBackend, err)
+		}
+		for _, entry := range page.Entries {
+			if err := w.Write(entry); err != nil {
+				return err
+			}
+		}
+		if page.Next == nil {
+			return w.Flush()
+		}
+		query.After = page.Next
+	}
+}
+
+// importReader reads the data streamed by an import.
+type importReader struct {
+	stream grpc.ClientStreamingServer[pb.ImportListRequest, pb.ImportListResponse]
+	data   []byte
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data = req.GetData()
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// exportWriter streams every write as a chunk of an export.
+type exportWriter struct {
+	stream grpc.ServerStreamingServer[pb.ExportListResponse]
+}
+
+func (w exportWriter) Write(p []byte) (int, error) {
+	// The message may be held on to after Send returns, so it gets a copy
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := w.stream.Send(&pb.ExportListResponse{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,141 @@
+package api_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	"github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/metrics"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/internal/api"
+	"github.com/TheJubadze/RateLimiter/internal/config"
+	"github.com/TheJubadze/RateLimiter/proto/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+var now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type importStream struct {
+	grpc.ServerStream
+	requests []*pb.ImportListRequest
+	resp     *pb.ImportListResponse
+}
+
+func (s *importStream) Recv() (*pb.ImportListRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *importStream) SendAndClose(resp *pb.ImportListResponse) error {
+	s.resp = resp
+	return nil
+}
+
+type exportStream struct {
+	grpc.ServerStream
+	data strings.Builder
+}
+
+func (s *exportStream) Send(resp *pb.ExportListResponse) error {
+	s.data.Write(resp.Data)
+	return nil
+}
+
+func newListServer() (*api.GrpcServer, *ipfilter.MockIPFilterService) {
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	server := api.NewGrpcServer(&config.Config{}, logruslogger.NewLogrusLogger("panic"), clock.NewFakeClock(now), newKeys(), new(bucket.MockBucketStorage), mockIPFilterService, &metrics.FakeRecorder{})
+	return server, mockIPFilterService
+}
+
+// chunks splits the data into requests at awkward places.
+func chunks(first *pb.ImportListRequest, data string) []*pb.ImportListRequest {
+	requests := []*pb.ImportListRequest{first}
+	for len(data) > 0 {
+		n := min(len(data), 7)
+		requests = append(requests, &pb.ImportListRequest{Data: []byte(data[:n])})
+		data = data[n:]
+	}
+	return requests
+}
+
+func TestImportList(t *testing.T) {
+	server, mockIPFilterService := newListServer()
+	mockIPFilterService.On("ImportBlacklist", []database.Entry{
+		{Network: "10.0.0.0/8", Metadata: database.Metadata{Reason: "feed"}},
+		{Network: "192.168.1.1/32", ExpiresAt: now.Add(time.Hour)},
+	}, true).Return(database.ImportResult{Imported: 2, Removed: 5}, nil)
+
+	stream := &importStream{requests: chunks(
+		&pb.ImportListRequest{List: pb.ListType_LIST_TYPE_BLACKLIST, Format: pb.ListFormat_LIST_FORMAT_CSV, Mode: pb.ImportMode_IMPORT_MODE_REPLACE},
+		"network,reason,expires_at\n10.0.0.0/8,feed\n192.168.1.1,,2024-01-01T01:00:00Z\n",
+	)}
+	require.NoError(t, server.ImportList(stream))
+
+	assert.Equal(t, int32(2), stream.resp.Imported)
+	assert.Equal(t, int32(5), stream.resp.Removed)
+	assert.Empty(t, stream.resp.Errors)
+	mockIPFilterService.AssertExpectations(t)
+}
+
+func TestImportListReportsInvalidLines(t *testing.T) {
+	server, mockIPFilterService := newListServer()
+
+	stream := &importStream{requests: chunks(
+		&pb.ImportListRequest{List: pb.ListType_LIST_TYPE_WHITELIST, Format: pb.ListFormat_LIST_FORMAT_JSON},
+		`{"network": "10.0.0.0/8"}`+"\n"+
+			`{"network": "10.0.0.0/99"}`+"\n"+
+			`{"network": "10.1.0.0/16", "expires_at": "2023-01-01T00:00:00Z"}`+"\n",
+	)}
+	require.NoError(t, server.ImportList(stream))
+
+	assert.Zero(t, stream.resp.Imported)
+	require.Len(t, stream.resp.Errors, 2)
+	assert.Equal(t, int32(2), stream.resp.Errors[0].Line)
+	assert.Equal(t, int32(3), stream.resp.Errors[1].Line)
+	assert.Equal(t, "already expired", stream.resp.Errors[1].Message)
+
+	// Nothing is imported from a file with invalid lines
+	mockIPFilterService.AssertNotCalled(t, "ImportWhitelist", mock.Anything, mock.Anything)
+}
+
+func TestImportListFails(t *testing.T) {
+	server, mockIPFilterService := newListServer()
+	mockIPFilterService.On("ImportWhitelist", mock.Anything, false).Return(database.ImportResult{}, errors.New("connection refused"))
+
+	stream := &importStream{requests: chunks(&pb.ImportListRequest{List: pb.ListType_LIST_TYPE_WHITELIST}, "10.0.0.0/8\n")}
+	assert.Error(t, server.ImportList(stream))
+
+	stream = &importStream{requests: chunks(&pb.ImportListRequest{}, "10.0.0.0/8\n")}
+	assert.Error(t, server.ImportList(stream))
+}
+
+func TestExportList(t *testing.T) {
+	server, mockIPFilterService := newListServer()
+	next := &database.Cursor{CreatedAt: now, Network: "10.0.0.0/8"}
+	mockIPFilterService.On("ListWhitelist", database.ListQuery{Limit: 1000}).Return(database.Page{
+		Entries: []database.Entry{{Network: "10.0.0.0/8"}},
+		Next:    next,
+	}, nil)
+	mockIPFilterService.On("ListWhitelist", database.ListQuery{Limit: 1000, After: next}).Return(database.Page{
+		Entries: []database.Entry{{Network: "192.168.0.0/16"}},
+	}, nil)
+
+	stream := &exportStream{}
+	require.NoError(t, server.ExportList(&pb.ExportListRequest{List: pb.ListType_LIST_TYPE_WHITELIST}, stream))
+
+	assert.Equal(t, "10.0.0.0/8\n192.168.0.0/16\n", stream.data.String())
+	mockIPFilterService.AssertExpectations(t)
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/clock"
+	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/metrics"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/internal/api"
+	"github.com/TheJubadze/RateLimiter/internal/config"
+	"github.com/TheJubadze/RateLimiter/proto/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serveBlocking serves a server whose bucket checks block until release is
+// closed or their call is canceled, and reports on inFlight when one starts.
+func serveBlocking(t *testing.T, shutdownTimeout time.Duration) (pb.RateLimiterClient, context.CancelFunc, <-chan error, <-chan struct{}, chan struct{}) {
+	t.Helper()
+	inFlight, release := make(chan struct{}, 1), make(chan struct{})
+
+	mockIPFilterService := new(ipfilter.MockIPFilterService)
+	mockIPFilterService.On("Evaluate", mock.Anything, mock.Anything).Return(ipfilter.Decision{}, false, nil)
+	mockBucketStorage := new(bucket.MockBucketStorage)
+	mockBucketStorage.On("CheckRateLimits", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		inFlight <- struct{}{}
+		select {
+		case <-release:
+		case <-args.Get(0).(context.Context).Done():
+		}
+	}).Return([]bucket.Result{{Allowed: true, Remaining: 4}}, nil)
+
+	cfg := config.CreateTestConfig(time.Minute, 5, 5, 5)
+	cfg.GrpcServer.ShutdownTimeout = shutdownTimeout
+	server := api.NewGrpcServer(cfg, logruslogger.NewLogrusLogger("panic"), systemclock.New(), newKeys(), mockBucketStorage, mockIPFilterService, &metrics.FakeRecorder{})
+
+	listener := bufconn.Listen(1 << 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener, func(*grpc.Server) {})
+	}()
+	t.Cleanup(cancel)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewRateLimiterClient(conn), cancel, served, inFlight, release
+}
+
+func TestServeDrainsCallsInFlight(t *testing.T) {
+	client, stop, served, inFlight, release := serveBlocking(t, time.Minute)
+
+	called := make(chan error, 1)
+	go func() {
+		_, err := client.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: "10.0.0.1"})
+		called <- err
+	}()
+	<-inFlight
+
+	stop()
+	select {
+	case <-served:
+		t.Fatal("the server stopped with a call in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(t, <-called)
+	assert.NoError(t, <-served)
+}
+
+func TestServeCancelsCallsAfterShutdownTimeout(t *testing.T) {
+	client, stop, served, inFlight, _ := serveBlocking(t, 10*time.Millisecond)
+
+	called := make(chan error, 1)
+	go func() {
+		_, err := client.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: "10.0.0.1"})
+		called <- err
+	}()
+	<-inFlight
+
+	stop()
+	assert.NoError(t, <-served)
+	err := <-called
+	assert.Error(t, err)
+	assert.Contains(t, []codes.Code{codes.Canceled, codes.Unavailable}, status.Code(err))
+}
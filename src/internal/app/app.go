@@ -1,52 +1,356 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/TheJubadze/RateLimiter/infrastructure/clock"
+	"github.com/TheJubadze/RateLimiter/infrastructure/feeds"
+	"github.com/TheJubadze/RateLimiter/infrastructure/health"
 	"github.com/TheJubadze/RateLimiter/infrastructure/ipfilter"
 	"github.com/TheJubadze/RateLimiter/infrastructure/logger"
+	"github.com/TheJubadze/RateLimiter/infrastructure/metrics"
+	"github.com/TheJubadze/RateLimiter/infrastructure/storage/iplists"
+	"github.com/TheJubadze/RateLimiter/infrastructure/storage/memory"
 	"github.com/TheJubadze/RateLimiter/infrastructure/storage/redis"
+	"github.com/TheJubadze/RateLimiter/infrastructure/tracing"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	healthiface "github.com/TheJubadze/RateLimiter/interfaces/health"
+	ipfilteriface "github.com/TheJubadze/RateLimiter/interfaces/ipfilter"
+	"github.com/TheJubadze/RateLimiter/interfaces/logger"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/bucket"
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
 	"github.com/TheJubadze/RateLimiter/internal/api"
+	"github.com/TheJubadze/RateLimiter/internal/bucketkey"
 	"github.com/TheJubadze/RateLimiter/internal/config"
 	"github.com/spf13/viper"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
-func StartServer(configFile *string) {
+// placeholderSecret is the secret configuration files used to ship with.
+const placeholderSecret = "change-me"
+
+// StartServer starts the server and serves until ctx is done, then shuts it
+// down: the health service reports NOT_SERVING, the calls in flight are
+// given grpc_server.shutdown_timeout to end, and what the server started is
+// closed, last started first.
+func StartServer(ctx context.Context, configFile string) error {
 	// Load configuration
-	cfg, err := initConfig(*configFile)
+	cfg, err := initConfig(configFile)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error reading config: %s\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error reading config: %w", err)
 	}
 
 	logrusLogger := logruslogger.NewLogrusLogger(cfg.Logger.Level)
 
-	// Initialize bucket storage (Redis-based)
-	bucketStorage := redisstorage.NewRedisBucketStorage(logrusLogger, cfg.Redis.Addr)
+	for _, algorithm := range []string{cfg.Algorithms.Login, cfg.Algorithms.Password, cfg.Algorithms.IP} {
+		if _, err := bucket.ParseAlgorithm(algorithm); err != nil {
+			return fmt.Errorf("invalid algorithms config: %w", err)
+		}
+	}
+
+	if cfg.BucketKeys.Secret == "" {
+		return errors.New("bucket_keys.secret must be set")
+	}
+	if cfg.BucketKeys.Secret == placeholderSecret {
+		return fmt.Errorf("bucket_keys.secret must not be the publicly known %q", placeholderSecret)
+	}
+	if len(cfg.BucketKeys.PreviousSecrets) > 0 && cfg.BucketKeys.RotatedAt.IsZero() {
+		return errors.New("bucket_keys.rotated_at must be set along with bucket_keys.previous_secrets")
+	}
+	if cfg.GrpcServer.HealthCheckInterval <= 0 {
+		return errors.New("grpc_server.health_check_interval must be positive")
+	}
+	if cfg.GrpcServer.ShutdownDelay >= cfg.GrpcServer.ShutdownTimeout {
+		return errors.New("grpc_server.shutdown_delay must be shorter than grpc_server.shutdown_timeout")
+	}
+	systemClock := systemclock.New()
+	keys := bucketkey.NewBuilder(
+		systemClock,
+		cfg.BucketKeys.Secret,
+		cfg.BucketKeys.PreviousSecrets,
+		cfg.BucketKeys.RotatedAt,
+		cfg.BucketKeys.GracePeriod,
+		cfg.BucketKeys.HashLogins,
+	)
+
+	var started closers
+	defer started.close(logrusLogger)
+
+	promMetrics := prometheusmetrics.New()
+	if cfg.Metrics.Port != "" {
+		metricsServer, err := startMetricsServer(cfg, logrusLogger, promMetrics)
+		if err != nil {
+			return fmt.Errorf("failed to serve metrics: %w", err)
+		}
+		started.add("metrics server", func() error {
+			return shutdown(cfg, metricsServer.Shutdown)
+		})
+	}
+
+	tracerProvider, err := newTracerProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	// Flush the spans of the calls the server ended with
+	if provider, ok := tracerProvider.(*sdktrace.TracerProvider); ok {
+		started.add("tracer provider", func() error {
+			return shutdown(cfg, provider.Shutdown)
+		})
+	}
+
+	// Initialize bucket storage
+	bucketStorage, err := newBucketStorage(cfg, logrusLogger, systemClock, keys)
+	if err != nil {
+		return err
+	}
+	if closer, ok := bucketStorage.(io.Closer); ok {
+		started.add("bucket storage", closer.Close)
+	}
+	checkers := map[string]healthiface.Checker{}
+	// The in-memory storage can't be unreachable
+	if checker, ok := bucketStorage.(healthiface.Checker); ok {
+		checkers[cfg.Storage.Backend] = checker
+	}
+	bucketStorage = promMetrics.InstrumentBucketStorage(bucketStorage, cfg.Storage.Backend)
+	bucketStorage = oteltracing.TraceBucketStorage(bucketStorage, cfg.Storage.Backend)
 
 	// Initialize whitelist/blacklist service
-	ipFilterService, err := ipfilter.NewService(cfg.SQLStorage.DSN)
+	repo, err := newRepository(cfg, promMetrics)
 	if err != nil {
-		logrusLogger.Fatalf("Failed to initialize IP filter service: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to connect to the lists database: %w", err)
 	}
+	ipFilterService, err := ipfilter.NewService(logrusLogger, systemClock, repo, cfg.SQLStorage.DSN, cfg.SQLStorage.SweepInterval, cfg.SQLStorage.CacheLists,
+		ipfilteriface.Precedence(cfg.IPFilter.Precedence))
+	if err != nil {
+		return fmt.Errorf("failed to initialize IP filter service: %w", err)
+	}
+	started.add("IP filter service", ipFilterService.Close)
+
+	// Keep the lists in sync with the configured feeds
+	if len(cfg.Feeds) > 0 {
+		syncer, syncerRepo, err := newFeedSyncer(cfg, logrusLogger, promMetrics)
+		if err != nil {
+			return fmt.Errorf("failed to initialize feeds: %w", err)
+		}
+		started.add("feeds repository", syncerRepo.Close)
+		started.add("feed syncer", syncer.Close)
+	}
+
+	checkers["postgres"] = repo
+	monitor := healthmonitor.NewMonitor(logrusLogger, checkers, cfg.GrpcServer.HealthCheckInterval, cfg.GrpcServer.HealthCheckTimeout)
+	started.add("health monitor", func() error {
+		monitor.Close()
+		return nil
+	})
+	register := func(grpcServer *grpc.Server) {
+		monitor.Register(grpcServer)
+		if cfg.GrpcServer.Reflection {
+			reflection.Register(grpcServer)
+		}
+	}
+
+	// Stop reporting the server as serving, and give the load balancers the
+	// shutdown delay to notice, before it stops accepting calls
+	serveCtx, stopServing := context.WithCancel(context.Background())
+	defer stopServing()
+	stop := context.AfterFunc(ctx, func() {
+		logrusLogger.Printf("Shutting down")
+		monitor.Shutdown()
+		if delay := cfg.GrpcServer.ShutdownDelay; delay > 0 {
+			logrusLogger.Printf("Still accepting calls for %s", delay)
+			time.Sleep(delay)
+		}
+		stopServing()
+	})
+	defer stop()
 
 	// Start the server
-	server := api.NewGrpcServer(cfg, logrusLogger, bucketStorage, ipFilterService)
-	if err := server.Start(); err != nil {
-		logrusLogger.Fatalf("Failed to start server: %v", err)
+	server := api.NewGrpcServer(cfg, logrusLogger, systemClock, keys, bucketStorage, promMetrics.InstrumentIPFilter(ipFilterService), promMetrics)
+	err = server.Start(serveCtx, register,
+		oteltracing.ServerOption(tracerProvider),
+		grpc.ChainUnaryInterceptor(promMetrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(promMetrics.StreamServerInterceptor()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// closers close what the server started.
+type closers []closer
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+func (c *closers) add(name string, close func() error) {
+	*c = append(*c, closer{name: name, close: close})
+}
+
+// close closes everything, last added first, so nothing is closed while
+// what was started after it may still use it.
+// The receiver is a pointer, so a deferred call closes what was added after
+// the defer statement.
+func (c *closers) close(logger logger.Logger) {
+	for i := len(*c) - 1; i >= 0; i-- {
+		if err := (*c)[i].close(); err != nil {
+			logger.Printf("Failed to close the %s: %v", (*c)[i].name, err)
+		}
 	}
 }
 
+// shutdown calls a shutdown function with the shutdown timeout.
+func shutdown(cfg *config.Config, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.GrpcServer.ShutdownTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// newTracerProvider creates the provider exporting the traces to the
+// configured collector, or recording none if there is no collector.
+func newTracerProvider(cfg *config.Config) (trace.TracerProvider, error) {
+	if cfg.Tracing.Endpoint == "" {
+		return noop.NewTracerProvider(), nil
+	}
+	return oteltracing.NewTracerProvider(context.Background(), cfg.Tracing.Endpoint, cfg.Tracing.Insecure, cfg.Tracing.SampleRatio)
+}
+
+func newBucketStorage(cfg *config.Config, logger logger.Logger, clock clock.Clock, keys *bucketkey.Builder) (bucket.Storage, error) {
+	switch cfg.Storage.Backend {
+	case "memory":
+		logger.Printf("Using in-memory bucket storage")
+		return memorystorage.NewMemoryBucketStorage(logger, clock, cfg.Storage.EvictionInterval), nil
+	case "redis":
+		bucketStorage := redisstorage.NewRedisBucketStorage(logger, clock, cfg.Redis.Addr)
+
+		// Move buckets written before key namespacing to their namespaced keys
+		migrated, err := bucketStorage.MigrateLegacyKeys(context.Background(), keys.FromLegacy)
+		if err != nil {
+			_ = bucketStorage.Close()
+			return nil, fmt.Errorf("failed to migrate legacy bucket keys: %w", err)
+		}
+		if migrated > 0 {
+			logger.Printf("Migrated %d legacy buckets", migrated)
+		}
+		return bucketStorage, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %q", cfg.Storage.Backend)
+	}
+}
+
+// startMetricsServer serves the metrics on /metrics in the background,
+// until the server is shut down.
+func startMetricsServer(cfg *config.Config, logger logger.Logger, metrics *prometheusmetrics.Metrics) (*http.Server, error) {
+	lis, err := net.Listen("tcp", ":"+cfg.Metrics.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Printf("Serving metrics on port %s", cfg.Metrics.Port)
+		if err := server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+			logger.Printf("Failed to serve metrics: %v", err)
+		}
+	}()
+	return server, nil
+}
+
+// newRepository connects to the lists database, counting its errors.
+// connectRepository connects to the lists database. Tests replace it to
+// start the server without Postgres.
+var connectRepository = func(dsn string) (iplists.Repository, error) {
+	repo, err := iplistsrepository.NewRepository(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func newRepository(cfg *config.Config, metrics *prometheusmetrics.Metrics) (iplists.Repository, error) {
+	repo, err := connectRepository(cfg.SQLStorage.DSN)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.InstrumentRepository(repo), nil
+}
+
+// newFeedSyncer starts syncing the feeds into a repository of its own,
+// which must be closed once the syncer is.
+func newFeedSyncer(cfg *config.Config, logger logger.Logger, metrics *prometheusmetrics.Metrics) (*feeds.Syncer, iplists.Repository, error) {
+	list := make([]feeds.Feed, len(cfg.Feeds))
+	for i, feed := range cfg.Feeds {
+		list[i] = feeds.Feed{
+			Name:            feed.Name,
+			Path:            feed.Path,
+			URL:             feed.URL,
+			Format:          feeds.Format(feed.Format),
+			RefreshInterval: feed.RefreshInterval,
+			List:            feed.List,
+		}
+	}
+
+	repo, err := newRepository(cfg, metrics)
+	if err != nil {
+		return nil, nil, err
+	}
+	syncer, err := feeds.NewSyncer(logger, repo, &http.Client{Timeout: time.Minute}, list)
+	if err != nil {
+		_ = repo.Close()
+		return nil, nil, err
+	}
+	return syncer, repo, nil
+}
+
 func initConfig(configPath string) (*config.Config, error) {
-	viper.SetConfigFile(configPath)
-	if err := viper.ReadInConfig(); err != nil {
+	// A viper of its own, so servers started in the same process don't
+	// share their configuration
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetDefault("grpc_server.health_check_interval", "5s")
+	v.SetDefault("grpc_server.health_check_timeout", "2s")
+	v.SetDefault("grpc_server.shutdown_delay", "0s")
+	v.SetDefault("grpc_server.shutdown_timeout", "30s")
+	v.SetDefault("storage.backend", "redis")
+	v.SetDefault("storage.eviction_interval", "1m")
+	v.SetDefault("sql_storage.sweep_interval", "1m")
+	v.SetDefault("sql_storage.cache_lists", true)
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("ip_filter.precedence", string(ipfilteriface.ListPrecedence))
+	v.SetDefault("algorithms.login", string(bucket.LeakyBucket))
+	v.SetDefault("algorithms.password", string(bucket.LeakyBucket))
+	v.SetDefault("algorithms.ip", string(bucket.LeakyBucket))
+	v.SetDefault("bucket_keys.rotated_at", "")
+	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
 
+	// Allow secrets to come from the environment, e.g. RATE_LIMITER_BUCKET_KEYS_SECRET
+	v.SetEnvPrefix("rate_limiter")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
 	cfg := &config.Config{}
-	err := viper.Unmarshal(cfg)
+	err := v.Unmarshal(cfg, viper.DecodeHook(config.DecodeHook()))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,148 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+	"github.com/TheJubadze/RateLimiter/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestStartServerReturnsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "Missing Secret",
+			config: "logger:\n  level: panic\n",
+			err:    "bucket_keys.secret must be set",
+		},
+		{
+			name:   "Placeholder Secret",
+			config: "logger:\n  level: panic\nbucket_keys:\n  secret: change-me\n",
+			err:    "bucket_keys.secret must not be",
+		},
+		{
+			name:   "Previous Secrets Without Rotation Time",
+			config: "logger:\n  level: panic\nbucket_keys:\n  secret: s\n  previous_secrets: [old]\n",
+			err:    "bucket_keys.rotated_at must be set",
+		},
+		{
+			name:   "Zero Health Check Interval",
+			config: "logger:\n  level: panic\nbucket_keys:\n  secret: s\ngrpc_server:\n  health_check_interval: 0s\n",
+			err:    "grpc_server.health_check_interval must be positive",
+		},
+		{
+			name:   "Shutdown Delay Longer Than Timeout",
+			config: "logger:\n  level: panic\nbucket_keys:\n  secret: s\ngrpc_server:\n  shutdown_delay: 1m\n  shutdown_timeout: 30s\n",
+			err:    "grpc_server.shutdown_delay must be shorter than grpc_server.shutdown_timeout",
+		},
+		{
+			name:   "Unknown Algorithm",
+			config: "logger:\n  level: panic\nalgorithms:\n  login: unknown\n",
+			err:    "invalid algorithms config",
+		},
+		{
+			name:   "Unknown Storage Backend",
+			config: "logger:\n  level: panic\nbucket_keys:\n  secret: s\nstorage:\n  backend: unknown\n",
+			err:    `unknown storage backend: "unknown"`,
+		},
+		{
+			name: "Unreachable Database",
+			config: "logger:\n  level: panic\nbucket_keys:\n  secret: s\nstorage:\n  backend: memory\n" +
+				"metrics:\n  port: 0\nsql_storage:\n  dsn: postgres://root@127.0.0.1:1/rate-limiter?sslmode=disable\n",
+			err: "failed to connect to the lists database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := app.StartServer(context.Background(), writeConfig(t, tt.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestStartServerReturnsErrorForMissingConfig(t *testing.T) {
+	err := app.StartServer(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "error reading config")
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestStartServerShutsDownWhenContextIsDone(t *testing.T) {
+	const shutdownDelay = 200 * time.Millisecond
+	repo := new(iplists.MockRepository)
+	repo.On("Ping", mock.Anything).Return(nil)
+	repo.On("Close").Return(nil)
+	app.SetRepository(t, repo)
+
+	port := freePort(t)
+	configFile := writeConfig(t, fmt.Sprintf("logger:\n  level: panic\nbucket_keys:\n  secret: s\nstorage:\n  backend: memory\n"+
+		"sql_storage:\n  cache_lists: false\n  sweep_interval: 0s\n"+
+		"grpc_server:\n  port: \"%d\"\n  shutdown_delay: %s\n  shutdown_timeout: 5s\n", port, shutdownDelay))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	returned := make(chan error, 1)
+	go func() { returned <- app.StartServer(ctx, configFile) }()
+
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return response.Status
+	}
+	require.Eventually(t, func() bool {
+		return status() == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The server still answers during the shutdown delay, as not serving
+	canceled := time.Now()
+	cancel()
+	assert.Eventually(t, func() bool {
+		return status() == healthpb.HealthCheckResponse_NOT_SERVING
+	}, shutdownDelay/2, 10*time.Millisecond)
+
+	select {
+	case err := <-returned:
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(canceled), shutdownDelay)
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartServer did not return after its context was done")
+	}
+	// What the server started was closed
+	repo.AssertCalled(t, "Close")
+}
@@ -0,0 +1,16 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/iplists"
+)
+
+// SetRepository makes the servers started by the test use repo instead of
+// connecting to the lists database.
+func SetRepository(t *testing.T, repo iplists.Repository) {
+	t.Helper()
+	connect := connectRepository
+	connectRepository = func(string) (iplists.Repository, error) { return repo, nil }
+	t.Cleanup(func() { connectRepository = connect })
+}
@@ -0,0 +1,121 @@
+package bucketkey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+)
+
+const (
+	namespacePrefix = "rl:"
+	loginPrefix     = namespacePrefix + "login:"
+	passwordPrefix  = namespacePrefix + "pwd:"
+	ipPrefix        = namespacePrefix + "ip:"
+)
+
+// Builder builds bucket storage keys for every rate limited dimension.
+// Each dimension has its own namespace, so a login that looks like an IP
+// or a password equal to a login never share a bucket.
+//
+// Passwords, and logins if enabled, never reach the storage in plaintext:
+// they are replaced with an HMAC-SHA256 keyed with the current secret.
+// Keys derived from previous secrets are still reported until the rotation
+// grace period, counted from when the secret was rotated, is over, so
+// buckets can be carried over to the new keys.
+type Builder struct {
+	clock           clock.Clock
+	secret          []byte
+	previousSecrets [][]byte
+	graceUntil      time.Time
+	hashLogins      bool
+}
+
+// NewBuilder creates a Builder whose previous secrets are reported until
+// gracePeriod after rotatedAt. rotatedAt is a fixed time rather than when
+// the Builder is created, so restarting the server doesn't extend it.
+func NewBuilder(clock clock.Clock, secret string, previousSecrets []string, rotatedAt time.Time, gracePeriod time.Duration, hashLogins bool) *Builder {
+	previous := make([][]byte, 0, len(previousSecrets))
+	for _, s := range previousSecrets {
+		previous = append(previous, []byte(s))
+	}
+
+	return &Builder{
+		clock:           clock,
+		secret:          []byte(secret),
+		previousSecrets: previous,
+		graceUntil:      rotatedAt.Add(gracePeriod),
+		hashLogins:      hashLogins,
+	}
+}
+
+func (b *Builder) Login(login string) string {
+	if b.hashLogins {
+		return loginPrefix + sign(b.secret, login)
+	}
+	return loginPrefix + login
+}
+
+func (b *Builder) Password(password string) string {
+	return passwordPrefix + sign(b.secret, password)
+}
+
+func (b *Builder) IP(ip string) string {
+	return ipPrefix + ip
+}
+
+// PreviousLogins returns the login keys derived from previous secrets,
+// or nil if logins are not hashed or the grace period is over.
+func (b *Builder) PreviousLogins(login string) []string {
+	if !b.hashLogins {
+		return nil
+	}
+	return b.previous(loginPrefix, login)
+}
+
+// PreviousPasswords returns the password keys derived from previous secrets,
+// or nil if the grace period is over.
+func (b *Builder) PreviousPasswords(password string) []string {
+	return b.previous(passwordPrefix, password)
+}
+
+// HashesLogins reports whether login keys are derived with the secret.
+func (b *Builder) HashesLogins() bool {
+	return b.hashLogins
+}
+
+// FromLegacy maps a raw key written before namespacing to the keys it
+// should be migrated to, or returns nil if the key is already namespaced.
+// An old key can't tell a login from a password, so anything that isn't
+// an IP address is carried over to both to keep an ongoing lockout in place.
+func (b *Builder) FromLegacy(raw string) []string {
+	if strings.HasPrefix(raw, namespacePrefix) {
+		return nil
+	}
+	if net.ParseIP(raw) != nil {
+		return []string{b.IP(raw)}
+	}
+	return []string{b.Login(raw), b.Password(raw)}
+}
+
+func (b *Builder) previous(prefix, value string) []string {
+	if len(b.previousSecrets) == 0 || b.clock.Now().After(b.graceUntil) {
+		return nil
+	}
+
+	keys := make([]string, 0, len(b.previousSecrets))
+	for _, secret := range b.previousSecrets {
+		keys = append(keys, prefix+sign(secret, value))
+	}
+	return keys
+}
+
+func sign(secret []byte, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,75 @@
+package bucketkey_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/infrastructure/clock"
+	"github.com/TheJubadze/RateLimiter/interfaces/clock"
+	"github.com/TheJubadze/RateLimiter/internal/bucketkey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderSeparatesNamespaces(t *testing.T) {
+	keys := bucketkey.NewBuilder(systemclock.New(), "secret", nil, time.Time{}, 0, false)
+
+	assert.NotEqual(t, keys.Login("10.0.0.1"), keys.IP("10.0.0.1"))
+	assert.NotEqual(t, keys.Login("secret"), keys.Password("secret"))
+	assert.NotEqual(t, keys.Password("10.0.0.1"), keys.IP("10.0.0.1"))
+}
+
+func TestBuilderHashesSecrets(t *testing.T) {
+	keys := bucketkey.NewBuilder(systemclock.New(), "secret", nil, time.Time{}, 0, true)
+
+	assert.NotContains(t, keys.Password("hunter2"), "hunter2")
+	assert.NotContains(t, keys.Login("alice"), "alice")
+	assert.Equal(t, keys.Password("hunter2"), keys.Password("hunter2"))
+	assert.NotEqual(t, keys.Password("hunter2"), bucketkey.NewBuilder(systemclock.New(), "other", nil, time.Time{}, 0, true).Password("hunter2"))
+	assert.Equal(t, "rl:login:alice", bucketkey.NewBuilder(systemclock.New(), "secret", nil, time.Time{}, 0, false).Login("alice"))
+}
+
+func TestBuilderPreviousSecrets(t *testing.T) {
+	old := bucketkey.NewBuilder(systemclock.New(), "old", nil, time.Time{}, 0, true)
+	keys := bucketkey.NewBuilder(systemclock.New(), "new", []string{"old"}, time.Now(), time.Hour, true)
+
+	assert.Equal(t, []string{old.Password("hunter2")}, keys.PreviousPasswords("hunter2"))
+	assert.Equal(t, []string{old.Login("alice")}, keys.PreviousLogins("alice"))
+	assert.Nil(t, bucketkey.NewBuilder(systemclock.New(), "new", nil, time.Time{}, 0, false).PreviousLogins("alice"))
+
+	rotatedAt := time.Unix(1700000000, 0)
+	fakeClock := clock.NewFakeClock(rotatedAt)
+	rotated := bucketkey.NewBuilder(fakeClock, "new", []string{"old"}, rotatedAt, time.Hour, true)
+	fakeClock.Advance(time.Hour)
+	assert.NotNil(t, rotated.PreviousPasswords("hunter2"))
+	fakeClock.Advance(time.Second)
+	assert.Nil(t, rotated.PreviousPasswords("hunter2"))
+}
+
+func TestBuilderGracePeriodSurvivesRestarts(t *testing.T) {
+	rotatedAt := time.Unix(1700000000, 0)
+	fakeClock := clock.NewFakeClock(rotatedAt)
+	keys := bucketkey.NewBuilder(fakeClock, "new", []string{"old"}, rotatedAt, time.Hour, true)
+	assert.NotNil(t, keys.PreviousPasswords("hunter2"))
+
+	// The server restarts half an hour in, and again once the grace period is over
+	fakeClock.Advance(30 * time.Minute)
+	keys = bucketkey.NewBuilder(fakeClock, "new", []string{"old"}, rotatedAt, time.Hour, true)
+	assert.NotNil(t, keys.PreviousPasswords("hunter2"))
+	fakeClock.Advance(31 * time.Minute)
+	assert.Nil(t, keys.PreviousPasswords("hunter2"))
+	keys = bucketkey.NewBuilder(fakeClock, "new", []string{"old"}, rotatedAt, time.Hour, true)
+	assert.Nil(t, keys.PreviousPasswords("hunter2"))
+	assert.Nil(t, keys.PreviousLogins("alice"))
+}
+
+func TestBuilderFromLegacy(t *testing.T) {
+	keys := bucketkey.NewBuilder(systemclock.New(), "secret", nil, time.Time{}, 0, true)
+
+	assert.Equal(t, []string{keys.IP("10.0.0.1")}, keys.FromLegacy("10.0.0.1"))
+	assert.Equal(t, []string{keys.Login("user"), keys.Password("user")}, keys.FromLegacy("user"))
+	assert.Nil(t, keys.FromLegacy(keys.Login("user")))
+	for _, key := range keys.FromLegacy("user") {
+		assert.False(t, strings.HasSuffix(key, "user"))
+	}
+}
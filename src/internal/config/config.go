@@ -1,16 +1,62 @@
 package config
 
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
 type loggerConfig struct {
 	Level string `mapstructure:"level"`
 }
 
 type grpcServerConfig struct {
 	Port string `mapstructure:"port"`
+	// Reflection lets clients such as grpcurl list the services
+	Reflection bool `mapstructure:"reflection"`
+	// HealthCheckInterval is how often Redis and Postgres are pinged
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+	// HealthCheckTimeout is how long a ping is given before its dependency
+	// is reported unreachable
+	HealthCheckTimeout time.Duration `mapstructure:"health_check_timeout"`
+	// ShutdownDelay is how long the server keeps accepting calls on shutdown
+	// after reporting NOT_SERVING, so load balancers stop routing to it first.
+	// It must be shorter than ShutdownTimeout
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+	// ShutdownTimeout is how long the calls in flight are given to end on
+	// shutdown, before they are canceled
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+type metricsConfig struct {
+	// Port serves the Prometheus metrics, disabled if empty
+	Port string `mapstructure:"port"`
+}
+
+type tracingConfig struct {
+	// Endpoint is the OTLP/gRPC collector the traces are exported to,
+	// disabled if empty
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
 }
 
 type sqlStorageConfig struct {
-	DSN           string `mapstructure:"dsn"`
-	MigrationsDir string `mapstructure:"migrations_dir"`
+	DSN           string        `mapstructure:"dsn"`
+	MigrationsDir string        `mapstructure:"migrations_dir"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+	CacheLists    bool          `mapstructure:"cache_lists"`
+}
+
+type ipFilterConfig struct {
+	Precedence string `mapstructure:"precedence"`
+}
+
+type storageConfig struct {
+	Backend          string        `mapstructure:"backend"`
+	EvictionInterval time.Duration `mapstructure:"eviction_interval"`
 }
 
 type redisConfig struct {
@@ -18,21 +64,106 @@ type redisConfig struct {
 }
 
 type loginLimitsConfig struct {
-	LeakRate int `mapstructure:"leak_rate"`
-	Login    int `mapstructure:"login_capacity"`
-	Password int `mapstructure:"password_capacity"`
-	IP       int `mapstructure:"ip_capacity"`
+	LeakRate time.Duration `mapstructure:"leak_rate"`
+	Login    int           `mapstructure:"login_capacity"`
+	Password int           `mapstructure:"password_capacity"`
+	IP       int           `mapstructure:"ip_capacity"`
+}
+
+type algorithmsConfig struct {
+	Login    string `mapstructure:"login"`
+	Password string `mapstructure:"password"`
+	IP       string `mapstructure:"ip"`
+}
+
+type bucketKeysConfig struct {
+	Secret          string   `mapstructure:"secret"`
+	PreviousSecrets []string `mapstructure:"previous_secrets"`
+	// RotatedAt is when the secret was rotated, the grace period of the
+	// previous secrets starts then
+	RotatedAt   time.Time     `mapstructure:"rotated_at"`
+	GracePeriod time.Duration `mapstructure:"rotation_grace_period"`
+	HashLogins  bool          `mapstructure:"hash_logins"`
+}
+
+type feedConfig struct {
+	Name            string        `mapstructure:"name"`
+	Path            string        `mapstructure:"path"`
+	URL             string        `mapstructure:"url"`
+	Format          string        `mapstructure:"format"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	List            string        `mapstructure:"list"`
 }
 
 type Config struct {
 	Logger      loggerConfig      `mapstructure:"logger"`
 	GrpcServer  grpcServerConfig  `mapstructure:"grpc_server"`
+	Metrics     metricsConfig     `mapstructure:"metrics"`
+	Tracing     tracingConfig     `mapstructure:"tracing"`
 	SQLStorage  sqlStorageConfig  `mapstructure:"sql_storage"`
+	IPFilter    ipFilterConfig    `mapstructure:"ip_filter"`
+	Storage     storageConfig     `mapstructure:"storage"`
 	Redis       redisConfig       `mapstructure:"redis"`
 	LoginLimits loginLimitsConfig `mapstructure:"leaky_bucket"`
+	Algorithms  algorithmsConfig  `mapstructure:"algorithms"`
+	BucketKeys  bucketKeysConfig  `mapstructure:"bucket_keys"`
+	Feeds       []feedConfig      `mapstructure:"feeds"`
+}
+
+// DecodeHook returns the hook used to decode the configuration file.
+// Durations may be written as Go duration strings ("1m30s") or, as in
+// older configuration files, as a plain number of seconds. Times are
+// written in RFC 3339, empty for none.
+func DecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		secondsToDurationHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		stringToTimeHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
 }
 
-func CreateTestConfig(leakRate int, loginCapacity int, passwordCapacity int, ipCapacity int) *Config {
+func stringToTimeHookFunc() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(time.Time{}) {
+			return data, nil
+		}
+		if data.(string) == "" {
+			return time.Time{}, nil
+		}
+		return time.Parse(time.RFC3339, data.(string))
+	}
+}
+
+func secondsToDurationHookFunc() mapstructure.DecodeHookFuncType {
+	return func(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if to != reflect.TypeOf(time.Duration(0)) {
+			return data, nil
+		}
+
+		var seconds float64
+		switch v := data.(type) {
+		case int:
+			seconds = float64(v)
+		case int64:
+			seconds = float64(v)
+		case float64:
+			seconds = v
+		case string:
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return data, nil
+			}
+			seconds = parsed
+		default:
+			return data, nil
+		}
+
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+}
+
+func CreateTestConfig(leakRate time.Duration, loginCapacity int, passwordCapacity int, ipCapacity int) *Config {
 	return &Config{
 		Logger: loggerConfig{
 			Level: "info",
@@ -44,6 +175,9 @@ func CreateTestConfig(leakRate int, loginCapacity int, passwordCapacity int, ipC
 			DSN:           "host=localhost port=5432 user=postgres password=postgres dbname=calendar sslmode=disable",
 			MigrationsDir: "file://migrations",
 		},
+		Storage: storageConfig{
+			Backend: "memory",
+		},
 		Redis: redisConfig{
 			Addr: "localhost:6379",
 		},
@@ -53,5 +187,13 @@ func CreateTestConfig(leakRate int, loginCapacity int, passwordCapacity int, ipC
 			Password: passwordCapacity,
 			IP:       ipCapacity,
 		},
+		Algorithms: algorithmsConfig{
+			Login:    "leaky_bucket",
+			Password: "leaky_bucket",
+			IP:       "leaky_bucket",
+		},
+		BucketKeys: bucketKeysConfig{
+			Secret: "test-secret",
+		},
 	}
 }
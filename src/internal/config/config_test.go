@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/internal/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeakRateDecoding(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "60", expected: time.Minute},
+		{value: "1.5", expected: 1500 * time.Millisecond},
+		{value: "1m30s", expected: 90 * time.Second},
+		{value: `"45"`, expected: 45 * time.Second},
+		{value: "250ms", expected: 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			v := viper.New()
+			v.SetConfigType("yaml")
+			require.NoError(t, v.ReadConfig(strings.NewReader("leaky_bucket:\n  leak_rate: "+tt.value)))
+
+			cfg := &config.Config{}
+			require.NoError(t, v.Unmarshal(cfg, viper.DecodeHook(config.DecodeHook())))
+			assert.Equal(t, tt.expected, cfg.LoginLimits.LeakRate)
+		})
+	}
+}
+
+func TestFeedsDecoding(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+feeds:
+  - name: spamhaus-drop
+    url: https://www.spamhaus.org/drop/drop.txt
+    format: drop
+    refresh_interval: 12h
+    list: blacklist
+  - name: local
+    path: /etc/rate-limiter/allow.netset
+    refresh_interval: 300
+    list: whitelist
+`)))
+
+	cfg := &config.Config{}
+	require.NoError(t, v.Unmarshal(cfg, viper.DecodeHook(config.DecodeHook())))
+	require.Len(t, cfg.Feeds, 2)
+	assert.Equal(t, "spamhaus-drop", cfg.Feeds[0].Name)
+	assert.Equal(t, "https://www.spamhaus.org/drop/drop.txt", cfg.Feeds[0].URL)
+	assert.Equal(t, "drop", cfg.Feeds[0].Format)
+	assert.Equal(t, 12*time.Hour, cfg.Feeds[0].RefreshInterval)
+	assert.Equal(t, "blacklist", cfg.Feeds[0].List)
+	assert.Equal(t, "/etc/rate-limiter/allow.netset", cfg.Feeds[1].Path)
+	assert.Equal(t, 5*time.Minute, cfg.Feeds[1].RefreshInterval)
+}
+
+func TestRotatedAtDecoding(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: `""`, expected: time.Time{}},
+		{value: "2024-10-01T12:00:00Z", expected: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{value: `"2024-10-01T14:00:00+02:00"`, expected: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			v := viper.New()
+			v.SetConfigType("yaml")
+			require.NoError(t, v.ReadConfig(strings.NewReader("bucket_keys:\n  rotated_at: "+tt.value)))
+
+			cfg := &config.Config{}
+			require.NoError(t, v.Unmarshal(cfg, viper.DecodeHook(config.DecodeHook())))
+			assert.True(t, tt.expected.Equal(cfg.BucketKeys.RotatedAt), cfg.BucketKeys.RotatedAt)
+		})
+	}
+}
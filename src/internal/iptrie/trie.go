@@ -0,0 +1,276 @@
+// Package iptrie implements a map of IP networks with fast lookups of the
+// networks containing an address.
+package iptrie
+
+import (
+	"net/netip"
+)
+
+// Trie maps IPv4 and IPv6 networks to values, stored in a path compressed
+// binary radix tree, so that finding the networks an address falls into
+// takes at most one step per bit of the address.
+// A Trie is not safe for concurrent use.
+type Trie[V any] struct {
+	v4   *node[V]
+	v6   *node[V]
+	size int
+}
+
+type node[V any] struct {
+	// prefix is masked, and shared by every network below the node
+	prefix netip.Prefix
+	// listed is whether prefix itself is in the set, rather than the node
+	// only joining its children
+	listed   bool
+	value    V
+	children [2]*node[V]
+}
+
+func New[V any]() *Trie[V] {
+	return &Trie[V]{}
+}
+
+// Len returns the number of networks in the trie.
+func (t *Trie[V]) Len() int {
+	return t.size
+}
+
+// Insert adds a network with its value to the trie, or replaces the value
+// if the network is already there. It reports whether the network was new.
+func (t *Trie[V]) Insert(prefix netip.Prefix, value V) bool {
+	prefix = normalize(prefix)
+	if !prefix.IsValid() {
+		return false
+	}
+
+	link := t.root(prefix.Addr())
+	for {
+		n := *link
+		if n == nil {
+			*link = &node[V]{prefix: prefix, listed: true, value: value}
+			t.size++
+			return true
+		}
+
+		common := commonBits(n.prefix, prefix)
+		if common == n.prefix.Bits() && common == prefix.Bits() {
+			n.value = value
+			if n.listed {
+				return false
+			}
+			n.listed = true
+			t.size++
+			return true
+		}
+
+		if common == n.prefix.Bits() {
+			link = &n.children[bit(prefix.Addr(), common)]
+			continue
+		}
+
+		// The new network branches off in the middle of the node's prefix
+		split := &node[V]{prefix: netip.PrefixFrom(prefix.Addr(), common).Masked()}
+		split.children[bit(n.prefix.Addr(), common)] = n
+		if common == prefix.Bits() {
+			split.listed = true
+			split.value = value
+		} else {
+			split.children[bit(prefix.Addr(), common)] = &node[V]{prefix: prefix, listed: true, value: value}
+		}
+		*link = split
+		t.size++
+		return true
+	}
+}
+
+// Remove removes a network from the trie. It reports whether the network
+// was in the trie.
+func (t *Trie[V]) Remove(prefix netip.Prefix) bool {
+	prefix = normalize(prefix)
+	if !prefix.IsValid() {
+		return false
+	}
+
+	var parent **node[V]
+	link := t.root(prefix.Addr())
+	for {
+		n := *link
+		if n == nil || commonBits(n.prefix, prefix) < n.prefix.Bits() {
+			return false
+		}
+		if n.prefix.Bits() == prefix.Bits() {
+			break
+		}
+		parent, link = link, &n.children[bit(prefix.Addr(), n.prefix.Bits())]
+	}
+
+	n := *link
+	if !n.listed {
+		return false
+	}
+	var zero V
+	n.listed = false
+	n.value = zero
+	t.size--
+
+	// Drop nodes that no longer join two branches
+	*link = n.compact()
+	if parent != nil {
+		*parent = (*parent).compact()
+	}
+	return true
+}
+
+// Contains reports whether an address falls into any network of the trie.
+func (t *Trie[V]) Contains(addr netip.Addr) bool {
+	return t.Match(addr, func(netip.Prefix, V) bool {
+		return true
+	})
+}
+
+// Match calls match for the networks an address falls into, shortest
+// prefix first, until it returns true. It reports whether it did.
+func (t *Trie[V]) Match(addr netip.Addr, match func(prefix netip.Prefix, value V) bool) bool {
+	addr = addr.Unmap()
+	n := *t.root(addr)
+	for n != nil && n.prefix.Contains(addr) {
+		if n.listed && match(n.prefix, n.value) {
+			return true
+		}
+		n = n.children[bit(addr, n.prefix.Bits())]
+	}
+	return false
+}
+
+// Walk calls fn for every network of the trie, shortest prefixes first
+// within each branch. fn must not modify the trie.
+func (t *Trie[V]) Walk(fn func(prefix netip.Prefix, value V)) {
+	var walk func(n *node[V])
+	walk = func(n *node[V]) {
+		if n == nil {
+			return
+		}
+		if n.listed {
+			fn(n.prefix, n.value)
+		}
+		walk(n.children[0])
+		walk(n.children[1])
+	}
+	walk(t.v4)
+	walk(t.v6)
+}
+
+// WalkSubnets calls fn for every network of the trie that falls within
+// prefix, prefix included, in the order of Walk. fn must not modify the trie.
+func (t *Trie[V]) WalkSubnets(prefix netip.Prefix, fn func(prefix netip.Prefix, value V)) {
+	prefix = normalize(prefix)
+	if !prefix.IsValid() {
+		return
+	}
+
+	var walk func(n *node[V])
+	walk = func(n *node[V]) {
+		if n == nil {
+			return
+		}
+		if n.listed {
+			fn(n.prefix, n.value)
+		}
+		walk(n.children[0])
+		walk(n.children[1])
+	}
+
+	// Go down to the first node within prefix, everything below it is too
+	n := *t.root(prefix.Addr())
+	for n != nil {
+		if n.prefix.Bits() >= prefix.Bits() {
+			if prefix.Contains(n.prefix.Addr()) {
+				walk(n)
+			}
+			return
+		}
+		if !n.prefix.Contains(prefix.Addr()) {
+			return
+		}
+		n = n.children[bit(prefix.Addr(), n.prefix.Bits())]
+	}
+}
+
+// Prefixes returns every network of the trie, in the order of Walk.
+func (t *Trie[V]) Prefixes() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, t.size)
+	t.Walk(func(prefix netip.Prefix, _ V) {
+		prefixes = append(prefixes, prefix)
+	})
+	return prefixes
+}
+
+func (t *Trie[V]) root(addr netip.Addr) **node[V] {
+	if addr.Is4() {
+		return &t.v4
+	}
+	return &t.v6
+}
+
+// compact returns what should take the place of an unlisted node: nothing
+// if it has no children, its only child if it has one, or itself.
+func (n *node[V]) compact() *node[V] {
+	if n.listed {
+		return n
+	}
+	switch {
+	case n.children[0] == nil:
+		return n.children[1]
+	case n.children[1] == nil:
+		return n.children[0]
+	default:
+		return n
+	}
+}
+
+// normalize masks the prefix and turns IPv4-mapped IPv6 networks into IPv4
+// ones, so a network is stored the same way however it was written.
+func normalize(prefix netip.Prefix) netip.Prefix {
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked()
+}
+
+// bit returns the bit of the address at the given position, counting from
+// the most significant one.
+func bit(addr netip.Addr, position int) int {
+	if position >= addr.BitLen() {
+		return 0
+	}
+	if addr.Is4() {
+		b := addr.As4()
+		return int(b[position/8]>>(7-position%8)) & 1
+	}
+	b := addr.As16()
+	return int(b[position/8]>>(7-position%8)) & 1
+}
+
+// commonBits returns the length of the longest prefix shared by both networks.
+func commonBits(a, b netip.Prefix) int {
+	limit := min(a.Bits(), b.Bits())
+	x, y := a.Addr().As16(), b.Addr().As16()
+	offset := 0
+	if a.Addr().Is4() {
+		// IPv4 addresses are in the last four bytes
+		offset = 96
+	}
+
+	for i := 0; i < limit; {
+		position := offset + i
+		if i%8 == 0 && limit-i >= 8 && x[position/8] == y[position/8] {
+			i += 8
+			continue
+		}
+		if (x[position/8]>>(7-position%8))&1 != (y[position/8]>>(7-position%8))&1 {
+			return i
+		}
+		i++
+	}
+	return limit
+}
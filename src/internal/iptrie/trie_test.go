@@ -0,0 +1,218 @@
+package iptrie_test
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/TheJubadze/RateLimiter/internal/iptrie"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContains(t *testing.T) {
+	trie := iptrie.New[struct{}]()
+	for _, network := range []string{"192.168.1.0/24", "10.0.0.0/8", "10.1.2.3/32", "2001:db8::/32", "::ffff:172.16.0.0/108"} {
+		assert.True(t, trie.Insert(netip.MustParsePrefix(network), struct{}{}), network)
+	}
+	assert.Equal(t, 5, trie.Len())
+
+	for ip, listed := range map[string]bool{
+		"192.168.1.1":          true,
+		"192.168.1.255":        true,
+		"192.168.2.1":          false,
+		"10.200.0.1":           true,
+		"10.1.2.3":             true,
+		"11.0.0.0":             false,
+		"172.16.5.5":           true,
+		"::ffff:192.168.1.1":   true,
+		"2001:db8:1::1":        true,
+		"2001:db9::1":          false,
+		"::1":                  false,
+		"0.0.0.0":              false,
+		"255.255.255.255":      false,
+		"ffff:ffff:ffff::ffff": false,
+	} {
+		assert.Equal(t, listed, trie.Contains(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestInsertNormalizes(t *testing.T) {
+	trie := iptrie.New[struct{}]()
+
+	assert.True(t, trie.Insert(netip.MustParsePrefix("192.168.1.7/24"), struct{}{}))
+	assert.False(t, trie.Insert(netip.MustParsePrefix("192.168.1.0/24"), struct{}{}))
+	assert.False(t, trie.Insert(netip.MustParsePrefix("::ffff:192.168.1.0/120"), struct{}{}))
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("192.168.1.0/24")}, trie.Prefixes())
+}
+
+func TestMatch(t *testing.T) {
+	trie := iptrie.New[string]()
+	trie.Insert(netip.MustParsePrefix("10.0.0.0/8"), "wide")
+	trie.Insert(netip.MustParsePrefix("10.1.0.0/16"), "narrow")
+	assert.False(t, trie.Insert(netip.MustParsePrefix("10.1.0.0/16"), "replaced"))
+
+	var matched []string
+	assert.False(t, trie.Match(netip.MustParseAddr("10.1.2.3"), func(prefix netip.Prefix, value string) bool {
+		matched = append(matched, prefix.String()+" "+value)
+		return false
+	}))
+	assert.Equal(t, []string{"10.0.0.0/8 wide", "10.1.0.0/16 replaced"}, matched)
+
+	assert.True(t, trie.Match(netip.MustParseAddr("10.1.2.3"), func(_ netip.Prefix, value string) bool {
+		return value == "replaced"
+	}))
+	assert.False(t, trie.Match(netip.MustParseAddr("10.2.0.1"), func(_ netip.Prefix, value string) bool {
+		return value == "replaced"
+	}))
+}
+
+func TestWalkSubnets(t *testing.T) {
+	trie := iptrie.New[struct{}]()
+	for _, network := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.2.0.0/16", "11.0.0.0/8", "2001:db8::/32"} {
+		trie.Insert(netip.MustParsePrefix(network), struct{}{})
+	}
+
+	for prefix, expected := range map[string][]string{
+		"10.0.0.0/8":          {"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.2.0.0/16"},
+		"10.1.0.0/16":         {"10.1.0.0/16", "10.1.2.0/24"},
+		"10.0.0.0/15":         {"10.1.0.0/16", "10.1.2.0/24"},
+		"10.1.2.3/32":         nil,
+		"0.0.0.0/0":           {"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.2.0.0/16", "11.0.0.0/8"},
+		"::ffff:10.1.0.0/112": {"10.1.0.0/16", "10.1.2.0/24"},
+		"2001::/16":           {"2001:db8::/32"},
+	} {
+		var walked []string
+		trie.WalkSubnets(netip.MustParsePrefix(prefix), func(prefix netip.Prefix, _ struct{}) {
+			walked = append(walked, prefix.String())
+		})
+		assert.Equal(t, expected, walked, prefix)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	trie := iptrie.New[struct{}]()
+	trie.Insert(netip.MustParsePrefix("10.0.0.0/8"), struct{}{})
+	trie.Insert(netip.MustParsePrefix("10.1.0.0/16"), struct{}{})
+	trie.Insert(netip.MustParsePrefix("10.2.0.0/16"), struct{}{})
+
+	assert.False(t, trie.Remove(netip.MustParsePrefix("10.0.0.0/16")))
+	assert.False(t, trie.Remove(netip.MustParsePrefix("10.0.0.0/7")))
+
+	assert.True(t, trie.Remove(netip.MustParsePrefix("10.0.0.0/8")))
+	assert.False(t, trie.Remove(netip.MustParsePrefix("10.0.0.0/8")))
+	assert.False(t, trie.Contains(netip.MustParseAddr("10.3.0.1")))
+	assert.True(t, trie.Contains(netip.MustParseAddr("10.1.0.1")))
+
+	assert.True(t, trie.Remove(netip.MustParsePrefix("10.1.0.0/16")))
+	assert.False(t, trie.Contains(netip.MustParseAddr("10.1.0.1")))
+	assert.True(t, trie.Contains(netip.MustParseAddr("10.2.0.1")))
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.2.0.0/16")}, trie.Prefixes())
+	assert.Equal(t, 1, trie.Len())
+}
+
+// TestMatchesLinearScan checks random networks and addresses against the
+// plain scan over every network.
+func TestMatchesLinearScan(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	trie := iptrie.New[struct{}]()
+	networks := randomNetworks(rnd, 200)
+	for _, network := range networks {
+		trie.Insert(netip.MustParsePrefix(network), struct{}{})
+	}
+
+	// Remove every other network, so compaction is exercised too
+	var kept []string
+	for i, network := range networks {
+		if i%2 == 0 {
+			trie.Remove(netip.MustParsePrefix(network))
+			continue
+		}
+		kept = append(kept, network)
+	}
+	for _, network := range kept {
+		trie.Insert(netip.MustParsePrefix(network), struct{}{})
+	}
+
+	for i := 0; i < 1000; i++ {
+		ip := randomIP(rnd)
+		assert.Equal(t, linearScan(kept, ip), trie.Contains(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func BenchmarkContains(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	networks := randomNetworks(rnd, 100000)
+	ips := make([]string, 1000)
+	for i := range ips {
+		ips[i] = randomIP(rnd)
+	}
+
+	b.Run("Trie", func(b *testing.B) {
+		trie := iptrie.New[struct{}]()
+		for _, network := range networks {
+			trie.Insert(netip.MustParsePrefix(network), struct{}{})
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			addr, err := netip.ParseAddr(ips[i%len(ips)])
+			if err == nil {
+				trie.Contains(addr)
+			}
+		}
+	})
+
+	b.Run("LinearScan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			linearScan(networks, ips[i%len(ips)])
+		}
+	})
+}
+
+// linearScan is how lookups worked before the trie: parse every network
+// and check them one by one.
+func linearScan(networks []string, ip string) bool {
+	addr := net.ParseIP(ip)
+	for _, network := range networks {
+		_, subnet, err := net.ParseCIDR(network)
+		if err != nil {
+			continue
+		}
+		if subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomNetworks returns networks that are mostly IPv4 and long enough not
+// to cover most of the address space.
+func randomNetworks(rnd *rand.Rand, n int) []string {
+	networks := make([]string, n)
+	for i := range networks {
+		if rnd.Intn(4) == 0 {
+			networks[i] = fmt.Sprintf("%s/%d", randomIPv6(rnd), 32+rnd.Intn(97))
+		} else {
+			networks[i] = fmt.Sprintf("%s/%d", randomIPv4(rnd), 16+rnd.Intn(17))
+		}
+	}
+	return networks
+}
+
+func randomIP(rnd *rand.Rand) string {
+	if rnd.Intn(4) == 0 {
+		return randomIPv6(rnd)
+	}
+	return randomIPv4(rnd)
+}
+
+func randomIPv4(rnd *rand.Rand) string {
+	return fmt.Sprintf("%d.%d.%d.%d", rnd.Intn(256), rnd.Intn(256), rnd.Intn(256), rnd.Intn(256))
+}
+
+// randomIPv6 returns addresses within 2001:db8::/24, so they share some
+// bits with the networks.
+func randomIPv6(rnd *rand.Rand) string {
+	return fmt.Sprintf("2001:d%02x:%x:%x::%x", rnd.Intn(256), rnd.Intn(65536), rnd.Intn(65536), rnd.Intn(65536))
+}
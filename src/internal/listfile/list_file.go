@@ -0,0 +1,393 @@
+package listfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+)
+
+// Format is how a list is written to a file.
+type Format string
+
+const (
+	// Text has one network or IP per line. Blank lines and anything after
+	// a # are ignored.
+	Text Format = "text"
+	// CSV has one entry per row, with the columns named by an optional
+	// header row, in the order of columns otherwise.
+	CSV Format = "csv"
+	// JSON has one entry per line, as a JSON object with the columns as keys.
+	JSON Format = "json"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{Text, CSV, JSON}
+
+// columns are the fields of an entry in CSV and JSON, labels are written as
+// key=value pairs separated by semicolons in CSV.
+var columns = []string{"network", "reason", "created_by", "ticket", "labels", "created_at", "expires_at", "action", "priority"}
+
+// maxLineLength bounds the lines of text and JSON files.
+const maxLineLength = 1 << 20
+
+// ParseFormat returns the format of the given name.
+func ParseFormat(format string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == format {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown list format: %q", format)
+}
+
+// Read reads the entries of a list and calls fn for each of them, with the
+// number of the line it starts on. Lines that are not valid entries are
+// passed to fn with an error instead, and reading goes on. Read stops at the
+// first error fn returns, and returns it.
+// Networks are returned masked, IPs as single address networks. The
+// creation time is not read, entries are created when they are imported.
+func Read(r io.Reader, format Format, fn func(line int, entry database.Entry, err error) error) error {
+	switch format {
+	case Text:
+		return readLines(r, fn, func(line string) (database.Entry, bool, error) {
+			if i := strings.IndexByte(line, '#'); i >= 0 {
+				line = line[:i]
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				return database.Entry{}, false, nil
+			}
+			network, err := parseNetwork(line)
+			return database.Entry{Network: network}, true, err
+		})
+	case CSV:
+		return readCSV(r, fn)
+	case JSON:
+		return readLines(r, fn, func(line string) (database.Entry, bool, error) {
+			if strings.TrimSpace(line) == "" {
+				return database.Entry{}, false, nil
+			}
+			var record jsonEntry
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				return database.Entry{}, true, err
+			}
+			entry, err := record.entry()
+			return entry, true, err
+		})
+	default:
+		return fmt.Errorf("unknown list format: %q", format)
+	}
+}
+
+// readLines calls parse for every line, skipping those it doesn't report
+// as entries.
+func readLines(r io.Reader, fn func(int, database.Entry, error) error, parse func(string) (database.Entry, bool, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for line := 1; scanner.Scan(); line++ {
+		entry, ok, err := parse(scanner.Text())
+		if !ok {
+			continue
+		}
+		if err := fn(line, entry, err); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func readCSV(r io.Reader, fn func(int, database.Entry, error) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header := columns
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// The rest of the record is skipped, the reader picks up on the next one
+			if err := fn(parseErr.StartLine, database.Entry{}, parseErr.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first && len(record) > 0 && record[0] == "network" {
+			if err := checkHeader(record); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			header = record
+			continue
+		}
+
+		entry, err := csvEntry(header, record)
+		if err := fn(line, entry, err); err != nil {
+			return err
+		}
+	}
+}
+
+func checkHeader(header []string) error {
+	for _, name := range header {
+		if !slices.Contains(columns, name) {
+			return fmt.Errorf("unknown column: %q", name)
+		}
+	}
+	return nil
+}
+
+func csvEntry(header, record []string) (database.Entry, error) {
+	if len(record) > len(header) {
+		return database.Entry{}, fmt.Errorf("expected at most %d columns, got %d", len(header), len(record))
+	}
+
+	var entry database.Entry
+	for i, value := range record {
+		var err error
+		switch header[i] {
+		case "network":
+			entry.Network, err = parseNetwork(value)
+		case "reason":
+			entry.Reason = value
+		case "created_by":
+			entry.CreatedBy = value
+		case "ticket":
+			entry.Ticket = value
+		case "labels":
+			entry.Labels, err = parseLabels(value)
+		case "expires_at":
+			entry.ExpiresAt, err = parseTime(value)
+		case "action":
+			entry.Action, err = parseAction(value)
+		case "priority":
+			entry.Priority, err = parsePriority(value)
+		}
+		if err != nil {
+			return database.Entry{}, err
+		}
+	}
+	if entry.Network == "" {
+		return database.Entry{}, errors.New("missing network")
+	}
+	return entry, nil
+}
+
+// Writer writes the entries of a list.
+type Writer struct {
+	format      Format
+	w           *bufio.Writer
+	csv         *csv.Writer
+	wroteHeader bool
+}
+
+// NewWriter creates a writer of the entries to w in format.
+func NewWriter(w io.Writer, format Format) *Writer {
+	buffered := bufio.NewWriter(w)
+	return &Writer{format: format, w: buffered, csv: csv.NewWriter(buffered)}
+}
+
+// Write writes an entry, preceded by the CSV header if it is the first one.
+func (w *Writer) Write(entry database.Entry) error {
+	switch w.format {
+	case Text:
+		_, err := fmt.Fprintln(w.w, entry.Network)
+		return err
+	case CSV:
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+		return w.csv.Write([]string{
+			entry.Network,
+			entry.Reason,
+			entry.CreatedBy,
+			entry.Ticket,
+			formatLabels(entry.Labels),
+			formatTime(entry.CreatedAt),
+			formatTime(entry.ExpiresAt),
+			string(entry.Action),
+			strconv.Itoa(entry.Priority),
+		})
+	case JSON:
+		line, err := json.Marshal(newJSONEntry(entry))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w.w, "%s\n", line)
+		return err
+	default:
+		return fmt.Errorf("unknown list format: %q", w.format)
+	}
+}
+
+// Flush writes out the entries written so far.
+func (w *Writer) Flush() error {
+	if w.format == CSV {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	return w.csv.Write(columns)
+}
+
+type jsonEntry struct {
+	Network   string            `json:"network"`
+	Reason    string            `json:"reason,omitempty"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	Ticket    string            `json:"ticket,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt *time.Time        `json:"created_at,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Action    string            `json:"action,omitempty"`
+	Priority  int               `json:"priority,omitempty"`
+}
+
+func newJSONEntry(entry database.Entry) jsonEntry {
+	record := jsonEntry{
+		Network:   entry.Network,
+		Reason:    entry.Reason,
+		CreatedBy: entry.CreatedBy,
+		Ticket:    entry.Ticket,
+		Labels:    entry.Labels,
+		Action:    string(entry.Action),
+		Priority:  entry.Priority,
+	}
+	if !entry.CreatedAt.IsZero() {
+		record.CreatedAt = &entry.CreatedAt
+	}
+	if !entry.ExpiresAt.IsZero() {
+		record.ExpiresAt = &entry.ExpiresAt
+	}
+	return record
+}
+
+func (e jsonEntry) entry() (database.Entry, error) {
+	if e.Network == "" {
+		return database.Entry{}, errors.New("missing network")
+	}
+	network, err := parseNetwork(e.Network)
+	if err != nil {
+		return database.Entry{}, err
+	}
+	action, err := parseAction(e.Action)
+	if err != nil {
+		return database.Entry{}, err
+	}
+
+	entry := database.Entry{
+		Network: network,
+		Metadata: database.Metadata{
+			Reason:    e.Reason,
+			CreatedBy: e.CreatedBy,
+			Ticket:    e.Ticket,
+			Labels:    e.Labels,
+		},
+		Rule: database.Rule{Action: action, Priority: e.Priority},
+	}
+	if e.ExpiresAt != nil {
+		entry.ExpiresAt = *e.ExpiresAt
+	}
+	return entry, nil
+}
+
+// parseNetwork parses a network, or an IP as the network holding only it.
+func parseNetwork(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		addr, addrErr := netip.ParseAddr(value)
+		if addrErr != nil || addr.Zone() != "" {
+			return "", fmt.Errorf("invalid network: %q", value)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	return prefix.Masked().String(), nil
+}
+
+func parseLabels(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label: %q", pair)
+		}
+		labels[key] = val
+	}
+	return labels, nil
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
+// parseAction parses an action, empty for the default one of the list.
+func parseAction(value string) (database.Action, error) {
+	action := database.Action(value)
+	if action != "" && !slices.Contains(database.Actions, action) {
+		return "", fmt.Errorf("unknown action: %q", value)
+	}
+	return action, nil
+}
+
+func parsePriority(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid priority: %q", value)
+	}
+	return priority, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
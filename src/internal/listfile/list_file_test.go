@@ -0,0 +1,159 @@
+package listfile_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheJubadze/RateLimiter/interfaces/storage/database"
+	"github.com/TheJubadze/RateLimiter/internal/listfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type line struct {
+	number int
+	entry  database.Entry
+	err    bool
+}
+
+func read(t *testing.T, format listfile.Format, input string) []line {
+	t.Helper()
+	var lines []line
+	err := listfile.Read(strings.NewReader(input), format, func(number int, entry database.Entry, err error) error {
+		lines = append(lines, line{number: number, entry: entry, err: err != nil})
+		return nil
+	})
+	require.NoError(t, err)
+	return lines
+}
+
+func TestReadText(t *testing.T) {
+	input := "# feed header\n10.1.2.3/8\n\n192.168.1.1 # a single IP\n2001:db8::/32\nnot a network\n"
+	assert.Equal(t, []line{
+		{number: 2, entry: database.Entry{Network: "10.0.0.0/8"}},
+		{number: 4, entry: database.Entry{Network: "192.168.1.1/32"}},
+		{number: 5, entry: database.Entry{Network: "2001:db8::/32"}},
+		{number: 6, err: true},
+	}, read(t, listfile.Text, input))
+}
+
+func TestReadCSV(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := "network,ticket,expires_at,labels,action,priority\n" +
+		"10.0.0.0/8,SEC-1,2030-01-02T03:04:05Z,source=feed;severity=high,limit,3\n" +
+		"192.168.1.1\n" +
+		"bad,SEC-2\n" +
+		"10.0.0.0/8,SEC-1,tomorrow\n" +
+		"10.0.0.0/8,,,,shout\n" +
+		"10.0.0.0/8,,,,deny,high\n"
+	assert.Equal(t, []line{
+		{number: 2, entry: database.Entry{
+			Network:   "10.0.0.0/8",
+			Metadata:  database.Metadata{Ticket: "SEC-1", Labels: map[string]string{"source": "feed", "severity": "high"}},
+			Rule:      database.Rule{Action: database.Limit, Priority: 3},
+			ExpiresAt: expiresAt,
+		}},
+		{number: 3, entry: database.Entry{Network: "192.168.1.1/32"}},
+		{number: 4, err: true},
+		{number: 5, err: true},
+		{number: 6, err: true},
+		{number: 7, err: true},
+	}, read(t, listfile.CSV, input))
+
+	err := listfile.Read(strings.NewReader("network,color\n"), listfile.CSV, func(int, database.Entry, error) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `{"network": "10.0.0.0/8", "reason": "abuse", "labels": {"source": "feed"}, "action": "limit"}` + "\n" +
+		"\n" +
+		`{"network": "10.0.0.0/33"}` + "\n" +
+		`{"reason": "no network"}` + "\n" +
+		"not json\n"
+	assert.Equal(t, []line{
+		{number: 1, entry: database.Entry{
+			Network:  "10.0.0.0/8",
+			Metadata: database.Metadata{Reason: "abuse", Labels: map[string]string{"source": "feed"}},
+			Rule:     database.Rule{Action: database.Limit},
+		}},
+		{number: 3, err: true},
+		{number: 4, err: true},
+		{number: 5, err: true},
+	}, read(t, listfile.JSON, input))
+}
+
+func TestReadStopsOnCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := listfile.Read(strings.NewReader("10.0.0.0/8\n10.0.0.0/16\n"), listfile.Text, func(int, database.Entry, error) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWriteReadsBack(t *testing.T) {
+	entries := []database.Entry{
+		{
+			Network:   "10.0.0.0/8",
+			Metadata:  database.Metadata{Reason: "abuse, again", CreatedBy: "alice", Ticket: "SEC-1", Labels: map[string]string{"a": "1", "b": "2"}},
+			Rule:      database.Rule{Action: database.BypassIPLimit, Priority: -2},
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ExpiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{Network: "2001:db8::/32", Rule: database.Rule{Action: database.Deny}, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, format := range listfile.Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := listfile.NewWriter(&buf, format)
+			for _, entry := range entries {
+				require.NoError(t, w.Write(entry))
+			}
+			require.NoError(t, w.Flush())
+
+			var read []database.Entry
+			err := listfile.Read(&buf, format, func(_ int, entry database.Entry, err error) error {
+				require.NoError(t, err)
+				read = append(read, entry)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Len(t, read, len(entries))
+
+			for i, entry := range entries {
+				// Entries are created anew when imported
+				entry.CreatedAt = time.Time{}
+				if format == listfile.Text {
+					entry = database.Entry{Network: entry.Network}
+				}
+				assert.Equal(t, entry.Network, read[i].Network)
+				assert.Equal(t, entry.Metadata, read[i].Metadata)
+				assert.Equal(t, entry.Rule, read[i].Rule)
+				assert.True(t, entry.ExpiresAt.Equal(read[i].ExpiresAt))
+			}
+		})
+	}
+}
+
+func TestWriteCSVHeaderOnly(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, listfile.NewWriter(&buf, listfile.CSV).Flush())
+	assert.Equal(t, "network,reason,created_by,ticket,labels,created_at,expires_at,action,priority\n", buf.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := listfile.ParseFormat("csv")
+	require.NoError(t, err)
+	assert.Equal(t, listfile.CSV, format)
+
+	_, err = listfile.ParseFormat("xml")
+	assert.Error(t, err)
+}
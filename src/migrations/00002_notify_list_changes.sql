@@ -0,0 +1,45 @@
+-- +goose Up
+
+-- Every replica listens on ip_lists_changes to keep its in-memory lists current
+-- +goose StatementBegin
+CREATE FUNCTION notify_ip_list_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'TRUNCATE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP)::text);
+    RETURN NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', OLD.network)::text);
+  ELSE
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', NEW.network)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER "whitelist_notify_change"
+  AFTER INSERT OR DELETE ON "whitelist"
+  FOR EACH ROW EXECUTE FUNCTION notify_ip_list_change();
+
+CREATE TRIGGER "whitelist_notify_truncate"
+  AFTER TRUNCATE ON "whitelist"
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_ip_list_change();
+
+CREATE TRIGGER "blacklist_notify_change"
+  AFTER INSERT OR DELETE ON "blacklist"
+  FOR EACH ROW EXECUTE FUNCTION notify_ip_list_change();
+
+CREATE TRIGGER "blacklist_notify_truncate"
+  AFTER TRUNCATE ON "blacklist"
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_ip_list_change();
+
+
+-- +goose Down
+
+DROP TRIGGER "blacklist_notify_truncate" ON "blacklist";
+DROP TRIGGER "blacklist_notify_change" ON "blacklist";
+DROP TRIGGER "whitelist_notify_truncate" ON "whitelist";
+DROP TRIGGER "whitelist_notify_change" ON "whitelist";
+DROP FUNCTION notify_ip_list_change();
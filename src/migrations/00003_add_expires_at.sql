@@ -0,0 +1,52 @@
+-- +goose Up
+
+ALTER TABLE "whitelist" ADD COLUMN "expires_at" timestamptz;
+ALTER TABLE "blacklist" ADD COLUMN "expires_at" timestamptz;
+
+CREATE INDEX "whitelist_expires_at_idx" ON "whitelist" ("expires_at") WHERE "expires_at" IS NOT NULL;
+CREATE INDEX "blacklist_expires_at_idx" ON "blacklist" ("expires_at") WHERE "expires_at" IS NOT NULL;
+
+-- Replicas need the expiry of inserted networks too
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_ip_list_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'TRUNCATE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP)::text);
+    RETURN NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', OLD.network)::text);
+  ELSE
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', NEW.network, 'expires_at', NEW.expires_at)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+
+-- +goose Down
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_ip_list_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'TRUNCATE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP)::text);
+    RETURN NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', OLD.network)::text);
+  ELSE
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', NEW.network)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP INDEX "blacklist_expires_at_idx";
+DROP INDEX "whitelist_expires_at_idx";
+ALTER TABLE "blacklist" DROP COLUMN "expires_at";
+ALTER TABLE "whitelist" DROP COLUMN "expires_at";
@@ -0,0 +1,28 @@
+-- +goose Up
+
+ALTER TABLE "whitelist"
+  ADD COLUMN "reason" text NOT NULL DEFAULT '',
+  ADD COLUMN "created_by" text NOT NULL DEFAULT '',
+  ADD COLUMN "ticket" text NOT NULL DEFAULT '',
+  ADD COLUMN "labels" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "blacklist"
+  ADD COLUMN "reason" text NOT NULL DEFAULT '',
+  ADD COLUMN "created_by" text NOT NULL DEFAULT '',
+  ADD COLUMN "ticket" text NOT NULL DEFAULT '',
+  ADD COLUMN "labels" jsonb NOT NULL DEFAULT '{}';
+
+
+-- +goose Down
+
+ALTER TABLE "blacklist"
+  DROP COLUMN "labels",
+  DROP COLUMN "ticket",
+  DROP COLUMN "created_by",
+  DROP COLUMN "reason";
+
+ALTER TABLE "whitelist"
+  DROP COLUMN "labels",
+  DROP COLUMN "ticket",
+  DROP COLUMN "created_by",
+  DROP COLUMN "reason";
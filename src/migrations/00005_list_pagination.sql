@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- Lists are paged by creation time, so every entry needs one
+UPDATE "whitelist" SET "created_at" = now() WHERE "created_at" IS NULL;
+UPDATE "blacklist" SET "created_at" = now() WHERE "created_at" IS NULL;
+ALTER TABLE "whitelist" ALTER COLUMN "created_at" SET NOT NULL;
+ALTER TABLE "blacklist" ALTER COLUMN "created_at" SET NOT NULL;
+
+CREATE INDEX "whitelist_created_at_network_idx" ON "whitelist" ("created_at", "network");
+CREATE INDEX "blacklist_created_at_network_idx" ON "blacklist" ("created_at", "network");
+
+
+-- +goose Down
+
+DROP INDEX "blacklist_created_at_network_idx";
+DROP INDEX "whitelist_created_at_network_idx";
+
+ALTER TABLE "blacklist" ALTER COLUMN "created_at" DROP NOT NULL;
+ALTER TABLE "whitelist" ALTER COLUMN "created_at" DROP NOT NULL;
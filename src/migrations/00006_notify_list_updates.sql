@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- Imports update listed networks in place, replicas pick up their new expiry
+CREATE TRIGGER "whitelist_notify_update"
+  AFTER UPDATE ON "whitelist"
+  FOR EACH ROW EXECUTE FUNCTION notify_ip_list_change();
+
+CREATE TRIGGER "blacklist_notify_update"
+  AFTER UPDATE ON "blacklist"
+  FOR EACH ROW EXECUTE FUNCTION notify_ip_list_change();
+
+
+-- +goose Down
+
+DROP TRIGGER "blacklist_notify_update" ON "blacklist";
+DROP TRIGGER "whitelist_notify_update" ON "whitelist";
@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- The feed an entry was pulled from, empty for entries added by hand
+ALTER TABLE "whitelist" ADD COLUMN "source" text NOT NULL DEFAULT '';
+ALTER TABLE "blacklist" ADD COLUMN "source" text NOT NULL DEFAULT '';
+
+CREATE INDEX "whitelist_source_idx" ON "whitelist" ("source") WHERE "source" <> '';
+CREATE INDEX "blacklist_source_idx" ON "blacklist" ("source") WHERE "source" <> '';
+
+
+-- +goose Down
+
+DROP INDEX "blacklist_source_idx";
+DROP INDEX "whitelist_source_idx";
+
+ALTER TABLE "blacklist" DROP COLUMN "source";
+ALTER TABLE "whitelist" DROP COLUMN "source";
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- Networks are stored as cidr so containment lookups can run in SQL, on a
+-- GiST index. Networks stored with host bits set are masked
+ALTER TABLE "whitelist" ALTER COLUMN "network" TYPE cidr USING network("network"::inet);
+ALTER TABLE "blacklist" ALTER COLUMN "network" TYPE cidr USING network("network"::inet);
+
+CREATE INDEX "whitelist_network_gist_idx" ON "whitelist" USING gist ("network" inet_ops);
+CREATE INDEX "blacklist_network_gist_idx" ON "blacklist" USING gist ("network" inet_ops);
+
+
+-- +goose Down
+
+DROP INDEX "blacklist_network_gist_idx";
+DROP INDEX "whitelist_network_gist_idx";
+
+ALTER TABLE "blacklist" ALTER COLUMN "network" TYPE varchar USING "network"::text;
+ALTER TABLE "whitelist" ALTER COLUMN "network" TYPE varchar USING "network"::text;
@@ -0,0 +1,62 @@
+-- +goose Up
+
+-- What happens to the requests from a network, and which of the networks
+-- holding an IP decides. Entries so far keep the action of their list
+ALTER TABLE "whitelist" ADD COLUMN "action" text NOT NULL DEFAULT 'allow';
+ALTER TABLE "blacklist" ADD COLUMN "action" text NOT NULL DEFAULT 'deny';
+ALTER TABLE "whitelist" ADD COLUMN "priority" integer NOT NULL DEFAULT 0;
+ALTER TABLE "blacklist" ADD COLUMN "priority" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE "whitelist" ADD CONSTRAINT "whitelist_action_check"
+  CHECK ("action" IN ('allow', 'deny', 'limit', 'bypass_ip_limit'));
+ALTER TABLE "blacklist" ADD CONSTRAINT "blacklist_action_check"
+  CHECK ("action" IN ('allow', 'deny', 'limit', 'bypass_ip_limit'));
+
+-- Replicas need the rule of inserted networks too
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_ip_list_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'TRUNCATE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP)::text);
+    RETURN NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', OLD.network)::text);
+  ELSE
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', NEW.network,
+      'expires_at', NEW.expires_at, 'action', NEW.action, 'priority', NEW.priority)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+
+-- +goose Down
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_ip_list_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'TRUNCATE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP)::text);
+    RETURN NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', OLD.network)::text);
+  ELSE
+    PERFORM pg_notify('ip_lists_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'network', NEW.network, 'expires_at', NEW.expires_at)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+ALTER TABLE "blacklist" DROP CONSTRAINT "blacklist_action_check";
+ALTER TABLE "whitelist" DROP CONSTRAINT "whitelist_action_check";
+
+ALTER TABLE "blacklist" DROP COLUMN "priority";
+ALTER TABLE "whitelist" DROP COLUMN "priority";
+ALTER TABLE "blacklist" DROP COLUMN "action";
+ALTER TABLE "whitelist" DROP COLUMN "action";
@@ -9,6 +9,8 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,6 +22,318 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type LimitType int32
+
+const (
+	LimitType_LIMIT_TYPE_UNSPECIFIED LimitType = 0
+	LimitType_LOGIN                  LimitType = 1
+	LimitType_PASSWORD               LimitType = 2
+	LimitType_IP                     LimitType = 3
+	LimitType_BLACKLIST              LimitType = 4
+)
+
+// Enum value maps for LimitType.
+var (
+	LimitType_name = map[int32]string{
+		0: "LIMIT_TYPE_UNSPECIFIED",
+		1: "LOGIN",
+		2: "PASSWORD",
+		3: "IP",
+		4: "BLACKLIST",
+	}
+	LimitType_value = map[string]int32{
+		"LIMIT_TYPE_UNSPECIFIED": 0,
+		"LOGIN":                  1,
+		"PASSWORD":               2,
+		"IP":                     3,
+		"BLACKLIST":              4,
+	}
+)
+
+func (x LimitType) Enum() *LimitType {
+	p := new(LimitType)
+	*p = x
+	return p
+}
+
+func (x LimitType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LimitType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_login_info_proto_enumTypes[0].Descriptor()
+}
+
+func (LimitType) Type() protoreflect.EnumType {
+	return &file_proto_login_info_proto_enumTypes[0]
+}
+
+func (x LimitType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LimitType.Descriptor instead.
+func (LimitType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{0}
+}
+
+// Entries are sorted by creation time
+type SortOrder int32
+
+const (
+	SortOrder_SORT_ORDER_OLDEST_FIRST SortOrder = 0
+	SortOrder_SORT_ORDER_NEWEST_FIRST SortOrder = 1
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_OLDEST_FIRST",
+		1: "SORT_ORDER_NEWEST_FIRST",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_OLDEST_FIRST": 0,
+		"SORT_ORDER_NEWEST_FIRST": 1,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_login_info_proto_enumTypes[1].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_proto_login_info_proto_enumTypes[1]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{1}
+}
+
+type ListFormat int32
+
+const (
+	// One network per line
+	ListFormat_LIST_FORMAT_TEXT ListFormat = 0
+	// One entry per row, with a header row naming the columns
+	ListFormat_LIST_FORMAT_CSV ListFormat = 1
+	// One entry per line, as a JSON object
+	ListFormat_LIST_FORMAT_JSON ListFormat = 2
+)
+
+// Enum value maps for ListFormat.
+var (
+	ListFormat_name = map[int32]string{
+		0: "LIST_FORMAT_TEXT",
+		1: "LIST_FORMAT_CSV",
+		2: "LIST_FORMAT_JSON",
+	}
+	ListFormat_value = map[string]int32{
+		"LIST_FORMAT_TEXT": 0,
+		"LIST_FORMAT_CSV":  1,
+		"LIST_FORMAT_JSON": 2,
+	}
+)
+
+func (x ListFormat) Enum() *ListFormat {
+	p := new(ListFormat)
+	*p = x
+	return p
+}
+
+func (x ListFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_login_info_proto_enumTypes[2].Descriptor()
+}
+
+func (ListFormat) Type() protoreflect.EnumType {
+	return &file_proto_login_info_proto_enumTypes[2]
+}
+
+func (x ListFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListFormat.Descriptor instead.
+func (ListFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{2}
+}
+
+type ImportMode int32
+
+const (
+	// Imported networks are added to the list, or updated if already listed
+	ImportMode_IMPORT_MODE_MERGE ImportMode = 0
+	// The list is replaced with the imported networks
+	ImportMode_IMPORT_MODE_REPLACE ImportMode = 1
+)
+
+// Enum value maps for ImportMode.
+var (
+	ImportMode_name = map[int32]string{
+		0: "IMPORT_MODE_MERGE",
+		1: "IMPORT_MODE_REPLACE",
+	}
+	ImportMode_value = map[string]int32{
+		"IMPORT_MODE_MERGE":   0,
+		"IMPORT_MODE_REPLACE": 1,
+	}
+)
+
+func (x ImportMode) Enum() *ImportMode {
+	p := new(ImportMode)
+	*p = x
+	return p
+}
+
+func (x ImportMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_login_info_proto_enumTypes[3].Descriptor()
+}
+
+func (ImportMode) Type() protoreflect.EnumType {
+	return &file_proto_login_info_proto_enumTypes[3]
+}
+
+func (x ImportMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportMode.Descriptor instead.
+func (ImportMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{3}
+}
+
+type RuleAction int32
+
+const (
+	// The default action of the list: whitelisted networks are allowed and
+	// blacklisted ones denied
+	RuleAction_RULE_ACTION_UNSPECIFIED RuleAction = 0
+	// Requests are authorized without checking any limit
+	RuleAction_RULE_ACTION_ALLOW RuleAction = 1
+	// Requests are rejected
+	RuleAction_RULE_ACTION_DENY RuleAction = 2
+	// Every limit is checked, as if the network was not listed
+	RuleAction_RULE_ACTION_LIMIT RuleAction = 3
+	// The login and password limits are checked, but not the IP one
+	RuleAction_RULE_ACTION_BYPASS_IP_LIMIT RuleAction = 4
+)
+
+// Enum value maps for RuleAction.
+var (
+	RuleAction_name = map[int32]string{
+		0: "RULE_ACTION_UNSPECIFIED",
+		1: "RULE_ACTION_ALLOW",
+		2: "RULE_ACTION_DENY",
+		3: "RULE_ACTION_LIMIT",
+		4: "RULE_ACTION_BYPASS_IP_LIMIT",
+	}
+	RuleAction_value = map[string]int32{
+		"RULE_ACTION_UNSPECIFIED":     0,
+		"RULE_ACTION_ALLOW":           1,
+		"RULE_ACTION_DENY":            2,
+		"RULE_ACTION_LIMIT":           3,
+		"RULE_ACTION_BYPASS_IP_LIMIT": 4,
+	}
+)
+
+func (x RuleAction) Enum() *RuleAction {
+	p := new(RuleAction)
+	*p = x
+	return p
+}
+
+func (x RuleAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RuleAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_login_info_proto_enumTypes[4].Descriptor()
+}
+
+func (RuleAction) Type() protoreflect.EnumType {
+	return &file_proto_login_info_proto_enumTypes[4]
+}
+
+func (x RuleAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RuleAction.Descriptor instead.
+func (RuleAction) EnumDescriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{4}
+}
+
+type ListType int32
+
+const (
+	ListType_LIST_TYPE_UNSPECIFIED ListType = 0
+	ListType_LIST_TYPE_WHITELIST   ListType = 1
+	ListType_LIST_TYPE_BLACKLIST   ListType = 2
+)
+
+// Enum value maps for ListType.
+var (
+	ListType_name = map[int32]string{
+		0: "LIST_TYPE_UNSPECIFIED",
+		1: "LIST_TYPE_WHITELIST",
+		2: "LIST_TYPE_BLACKLIST",
+	}
+	ListType_value = map[string]int32{
+		"LIST_TYPE_UNSPECIFIED": 0,
+		"LIST_TYPE_WHITELIST":   1,
+		"LIST_TYPE_BLACKLIST":   2,
+	}
+)
+
+func (x ListType) Enum() *ListType {
+	p := new(ListType)
+	*p = x
+	return p
+}
+
+func (x ListType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_login_info_proto_enumTypes[5].Descriptor()
+}
+
+func (ListType) Type() protoreflect.EnumType {
+	return &file_proto_login_info_proto_enumTypes[5]
+}
+
+func (x ListType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListType.Descriptor instead.
+func (ListType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{5}
+}
+
 // Request and Response for the Authorize method
 type AuthorizeRequest struct {
 	state         protoimpl.MessageState
@@ -91,6 +405,14 @@ type AuthorizeResponse struct {
 
 	Authorized bool   `protobuf:"varint,1,opt,name=authorized,proto3" json:"authorized,omitempty"`
 	Message    string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// The limit that rejected the request, unspecified if it is authorized
+	Limit LimitType `protobuf:"varint,3,opt,name=limit,proto3,enum=api.LimitType" json:"limit,omitempty"`
+	// Requests left in each bucket that was checked. A rejected request is
+	// recorded in no bucket, but the counts are as if it was
+	Remaining *RemainingQuota `protobuf:"bytes,4,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	// How long until a rejected request could be authorized, unset if it
+	// never can, e.g. when the IP is blacklisted
+	RetryAfter *durationpb.Duration `protobuf:"bytes,5,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
 }
 
 func (x *AuthorizeResponse) Reset() {
@@ -139,6 +461,92 @@ func (x *AuthorizeResponse) GetMessage() string {
 	return ""
 }
 
+func (x *AuthorizeResponse) GetLimit() LimitType {
+	if x != nil {
+		return x.Limit
+	}
+	return LimitType_LIMIT_TYPE_UNSPECIFIED
+}
+
+func (x *AuthorizeResponse) GetRemaining() *RemainingQuota {
+	if x != nil {
+		return x.Remaining
+	}
+	return nil
+}
+
+func (x *AuthorizeResponse) GetRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return nil
+}
+
+// Unset fields are buckets that were not checked, because the request
+// didn't carry the value
+type RemainingQuota struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Login    *int32 `protobuf:"varint,1,opt,name=login,proto3,oneof" json:"login,omitempty"`
+	Password *int32 `protobuf:"varint,2,opt,name=password,proto3,oneof" json:"password,omitempty"`
+	Ip       *int32 `protobuf:"varint,3,opt,name=ip,proto3,oneof" json:"ip,omitempty"`
+}
+
+func (x *RemainingQuota) Reset() {
+	*x = RemainingQuota{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemainingQuota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemainingQuota) ProtoMessage() {}
+
+func (x *RemainingQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemainingQuota.ProtoReflect.Descriptor instead.
+func (*RemainingQuota) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RemainingQuota) GetLogin() int32 {
+	if x != nil && x.Login != nil {
+		return *x.Login
+	}
+	return 0
+}
+
+func (x *RemainingQuota) GetPassword() int32 {
+	if x != nil && x.Password != nil {
+		return *x.Password
+	}
+	return 0
+}
+
+func (x *RemainingQuota) GetIp() int32 {
+	if x != nil && x.Ip != nil {
+		return *x.Ip
+	}
+	return 0
+}
+
 // Request and Response for ResetBucket method
 type ResetBucketRequest struct {
 	state         protoimpl.MessageState
@@ -152,7 +560,7 @@ type ResetBucketRequest struct {
 func (x *ResetBucketRequest) Reset() {
 	*x = ResetBucketRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[2]
+		mi := &file_proto_login_info_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -165,7 +573,7 @@ func (x *ResetBucketRequest) String() string {
 func (*ResetBucketRequest) ProtoMessage() {}
 
 func (x *ResetBucketRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[2]
+	mi := &file_proto_login_info_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -178,7 +586,7 @@ func (x *ResetBucketRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResetBucketRequest.ProtoReflect.Descriptor instead.
 func (*ResetBucketRequest) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{2}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ResetBucketRequest) GetLogin() string {
@@ -206,7 +614,7 @@ type ResetBucketResponse struct {
 func (x *ResetBucketResponse) Reset() {
 	*x = ResetBucketResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[3]
+		mi := &file_proto_login_info_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -219,7 +627,7 @@ func (x *ResetBucketResponse) String() string {
 func (*ResetBucketResponse) ProtoMessage() {}
 
 func (x *ResetBucketResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[3]
+	mi := &file_proto_login_info_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -232,7 +640,7 @@ func (x *ResetBucketResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResetBucketResponse.ProtoReflect.Descriptor instead.
 func (*ResetBucketResponse) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{3}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ResetBucketResponse) GetMessage() string {
@@ -249,12 +657,27 @@ type AddToWhitelistRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// How long the network stays whitelisted, for good if unset
+	Ttl *durationpb.Duration `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// Why the network is whitelisted
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Who whitelisted the network
+	CreatedBy string `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// Reference to the issue the network was whitelisted for
+	Ticket string            `protobuf:"bytes,5,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Labels map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Add the network even if it overlaps listed networks
+	Force bool `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`
+	// What happens to the requests from the network, they are allowed if unset
+	Action RuleAction `protobuf:"varint,8,opt,name=action,proto3,enum=api.RuleAction" json:"action,omitempty"`
+	// Of the rules matching a request, the one of highest priority decides
+	Priority int32 `protobuf:"varint,9,opt,name=priority,proto3" json:"priority,omitempty"`
 }
 
 func (x *AddToWhitelistRequest) Reset() {
 	*x = AddToWhitelistRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[4]
+		mi := &file_proto_login_info_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -267,7 +690,7 @@ func (x *AddToWhitelistRequest) String() string {
 func (*AddToWhitelistRequest) ProtoMessage() {}
 
 func (x *AddToWhitelistRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[4]
+	mi := &file_proto_login_info_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -280,7 +703,7 @@ func (x *AddToWhitelistRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddToWhitelistRequest.ProtoReflect.Descriptor instead.
 func (*AddToWhitelistRequest) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{4}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *AddToWhitelistRequest) GetIp() string {
@@ -290,18 +713,77 @@ func (x *AddToWhitelistRequest) GetIp() string {
 	return ""
 }
 
+func (x *AddToWhitelistRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *AddToWhitelistRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AddToWhitelistRequest) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *AddToWhitelistRequest) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+func (x *AddToWhitelistRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *AddToWhitelistRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *AddToWhitelistRequest) GetAction() RuleAction {
+	if x != nil {
+		return x.Action
+	}
+	return RuleAction_RULE_ACTION_UNSPECIFIED
+}
+
+func (x *AddToWhitelistRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
 type AddToWhitelistResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// The listed networks the network overlaps. Unless forced, the call
+	// fails with FAILED_PRECONDITION instead if there are any
+	Overlaps []*Overlap `protobuf:"bytes,2,rep,name=overlaps,proto3" json:"overlaps,omitempty"`
 }
 
 func (x *AddToWhitelistResponse) Reset() {
 	*x = AddToWhitelistResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[5]
+		mi := &file_proto_login_info_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -314,7 +796,7 @@ func (x *AddToWhitelistResponse) String() string {
 func (*AddToWhitelistResponse) ProtoMessage() {}
 
 func (x *AddToWhitelistResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[5]
+	mi := &file_proto_login_info_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -327,7 +809,7 @@ func (x *AddToWhitelistResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddToWhitelistResponse.ProtoReflect.Descriptor instead.
 func (*AddToWhitelistResponse) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{5}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *AddToWhitelistResponse) GetMessage() string {
@@ -337,6 +819,13 @@ func (x *AddToWhitelistResponse) GetMessage() string {
 	return ""
 }
 
+func (x *AddToWhitelistResponse) GetOverlaps() []*Overlap {
+	if x != nil {
+		return x.Overlaps
+	}
+	return nil
+}
+
 // Request and Response for RemoveFromWhitelist method
 type RemoveFromWhitelistRequest struct {
 	state         protoimpl.MessageState
@@ -349,7 +838,7 @@ type RemoveFromWhitelistRequest struct {
 func (x *RemoveFromWhitelistRequest) Reset() {
 	*x = RemoveFromWhitelistRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[6]
+		mi := &file_proto_login_info_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -362,7 +851,7 @@ func (x *RemoveFromWhitelistRequest) String() string {
 func (*RemoveFromWhitelistRequest) ProtoMessage() {}
 
 func (x *RemoveFromWhitelistRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[6]
+	mi := &file_proto_login_info_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -375,7 +864,7 @@ func (x *RemoveFromWhitelistRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoveFromWhitelistRequest.ProtoReflect.Descriptor instead.
 func (*RemoveFromWhitelistRequest) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{6}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *RemoveFromWhitelistRequest) GetIp() string {
@@ -396,7 +885,7 @@ type RemoveFromWhitelistResponse struct {
 func (x *RemoveFromWhitelistResponse) Reset() {
 	*x = RemoveFromWhitelistResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[7]
+		mi := &file_proto_login_info_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -409,7 +898,7 @@ func (x *RemoveFromWhitelistResponse) String() string {
 func (*RemoveFromWhitelistResponse) ProtoMessage() {}
 
 func (x *RemoveFromWhitelistResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[7]
+	mi := &file_proto_login_info_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -422,7 +911,7 @@ func (x *RemoveFromWhitelistResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoveFromWhitelistResponse.ProtoReflect.Descriptor instead.
 func (*RemoveFromWhitelistResponse) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{7}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *RemoveFromWhitelistResponse) GetMessage() string {
@@ -439,12 +928,27 @@ type AddToBlacklistRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// How long the network stays blacklisted, for good if unset
+	Ttl *durationpb.Duration `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// Why the network is blacklisted
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Who blacklisted the network
+	CreatedBy string `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// Reference to the issue the network was blacklisted for
+	Ticket string            `protobuf:"bytes,5,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Labels map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Add the network even if it overlaps listed networks
+	Force bool `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`
+	// What happens to the requests from the network, they are denied if unset
+	Action RuleAction `protobuf:"varint,8,opt,name=action,proto3,enum=api.RuleAction" json:"action,omitempty"`
+	// Of the rules matching a request, the one of highest priority decides
+	Priority int32 `protobuf:"varint,9,opt,name=priority,proto3" json:"priority,omitempty"`
 }
 
 func (x *AddToBlacklistRequest) Reset() {
 	*x = AddToBlacklistRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[8]
+		mi := &file_proto_login_info_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -457,7 +961,7 @@ func (x *AddToBlacklistRequest) String() string {
 func (*AddToBlacklistRequest) ProtoMessage() {}
 
 func (x *AddToBlacklistRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[8]
+	mi := &file_proto_login_info_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -470,7 +974,7 @@ func (x *AddToBlacklistRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddToBlacklistRequest.ProtoReflect.Descriptor instead.
 func (*AddToBlacklistRequest) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{8}
+	return file_proto_login_info_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *AddToBlacklistRequest) GetIp() string {
@@ -480,18 +984,77 @@ func (x *AddToBlacklistRequest) GetIp() string {
 	return ""
 }
 
+func (x *AddToBlacklistRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *AddToBlacklistRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AddToBlacklistRequest) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *AddToBlacklistRequest) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+func (x *AddToBlacklistRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *AddToBlacklistRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *AddToBlacklistRequest) GetAction() RuleAction {
+	if x != nil {
+		return x.Action
+	}
+	return RuleAction_RULE_ACTION_UNSPECIFIED
+}
+
+func (x *AddToBlacklistRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
 type AddToBlacklistResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// The listed networks the network overlaps. Unless forced, the call
+	// fails with FAILED_PRECONDITION instead if there are any
+	Overlaps []*Overlap `protobuf:"bytes,2,rep,name=overlaps,proto3" json:"overlaps,omitempty"`
 }
 
 func (x *AddToBlacklistResponse) Reset() {
 	*x = AddToBlacklistResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[9]
+		mi := &file_proto_login_info_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -501,10 +1064,728 @@ func (x *AddToBlacklistResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddToBlacklistResponse) ProtoMessage() {}
+func (*AddToBlacklistResponse) ProtoMessage() {}
+
+func (x *AddToBlacklistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddToBlacklistResponse.ProtoReflect.Descriptor instead.
+func (*AddToBlacklistResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AddToBlacklistResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *AddToBlacklistResponse) GetOverlaps() []*Overlap {
+	if x != nil {
+		return x.Overlaps
+	}
+	return nil
+}
+
+// Request and Response for RemoveFromBlacklist method
+type RemoveFromBlacklistRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+}
+
+func (x *RemoveFromBlacklistRequest) Reset() {
+	*x = RemoveFromBlacklistRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveFromBlacklistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFromBlacklistRequest) ProtoMessage() {}
+
+func (x *RemoveFromBlacklistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFromBlacklistRequest.ProtoReflect.Descriptor instead.
+func (*RemoveFromBlacklistRequest) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RemoveFromBlacklistRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+type RemoveFromBlacklistResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *RemoveFromBlacklistResponse) Reset() {
+	*x = RemoveFromBlacklistResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveFromBlacklistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFromBlacklistResponse) ProtoMessage() {}
+
+func (x *RemoveFromBlacklistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFromBlacklistResponse.ProtoReflect.Descriptor instead.
+func (*RemoveFromBlacklistResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RemoveFromBlacklistResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Request and Response for InspectNetwork method
+type InspectNetworkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// An IP or a network
+	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+}
+
+func (x *InspectNetworkRequest) Reset() {
+	*x = InspectNetworkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InspectNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectNetworkRequest) ProtoMessage() {}
+
+func (x *InspectNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectNetworkRequest.ProtoReflect.Descriptor instead.
+func (*InspectNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *InspectNetworkRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+type InspectNetworkResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The listed networks the IP or network falls into, whitelist first,
+	// shortest prefix first
+	Entries []*ListEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *InspectNetworkResponse) Reset() {
+	*x = InspectNetworkResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InspectNetworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectNetworkResponse) ProtoMessage() {}
+
+func (x *InspectNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectNetworkResponse.ProtoReflect.Descriptor instead.
+func (*InspectNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *InspectNetworkResponse) GetEntries() []*ListEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// Request and Response for ListWhitelist and ListBlacklist methods
+type ListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// How many entries to return, 100 if unset, at most 1000
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// The next_page_token of the previous page, unset for the first page. The
+	// other fields must stay the same from page to page
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Keep only the networks holding this IP or network
+	Contains string    `protobuf:"bytes,3,opt,name=contains,proto3" json:"contains,omitempty"`
+	Order    SortOrder `protobuf:"varint,4,opt,name=order,proto3,enum=api.SortOrder" json:"order,omitempty"`
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListRequest) GetContains() string {
+	if x != nil {
+		return x.Contains
+	}
+	return ""
+}
+
+func (x *ListRequest) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_SORT_ORDER_OLDEST_FIRST
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*ListEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Token to get the next page with, unset on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListResponse) GetEntries() []*ListEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Request and Response for ImportList method. The file is streamed in
+// chunks, split anywhere
+type ImportListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The list, format and mode are read from the first message
+	List   ListType   `protobuf:"varint,1,opt,name=list,proto3,enum=api.ListType" json:"list,omitempty"`
+	Format ListFormat `protobuf:"varint,2,opt,name=format,proto3,enum=api.ListFormat" json:"format,omitempty"`
+	Mode   ImportMode `protobuf:"varint,3,opt,name=mode,proto3,enum=api.ImportMode" json:"mode,omitempty"`
+	Data   []byte     `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ImportListRequest) Reset() {
+	*x = ImportListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportListRequest) ProtoMessage() {}
+
+func (x *ImportListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportListRequest.ProtoReflect.Descriptor instead.
+func (*ImportListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ImportListRequest) GetList() ListType {
+	if x != nil {
+		return x.List
+	}
+	return ListType_LIST_TYPE_UNSPECIFIED
+}
+
+func (x *ImportListRequest) GetFormat() ListFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ListFormat_LIST_FORMAT_TEXT
+}
+
+func (x *ImportListRequest) GetMode() ImportMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ImportMode_IMPORT_MODE_MERGE
+}
+
+func (x *ImportListRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ImportListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Networks listed or updated
+	Imported int32 `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	// Networks removed because the import replaced the list
+	Removed int32 `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
+	// Lines that are not valid entries. If there are any, nothing is imported
+	Errors []*LineError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+}
+
+func (x *ImportListResponse) Reset() {
+	*x = ImportListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportListResponse) ProtoMessage() {}
+
+func (x *ImportListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportListResponse.ProtoReflect.Descriptor instead.
+func (*ImportListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ImportListResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportListResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *ImportListResponse) GetErrors() []*LineError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type LineError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Line    int32  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *LineError) Reset() {
+	*x = LineError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LineError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineError) ProtoMessage() {}
+
+func (x *LineError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineError.ProtoReflect.Descriptor instead.
+func (*LineError) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *LineError) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LineError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Request and Response for ExportList method. The file is streamed in
+// chunks, split anywhere
+type ExportListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	List   ListType   `protobuf:"varint,1,opt,name=list,proto3,enum=api.ListType" json:"list,omitempty"`
+	Format ListFormat `protobuf:"varint,2,opt,name=format,proto3,enum=api.ListFormat" json:"format,omitempty"`
+}
+
+func (x *ExportListRequest) Reset() {
+	*x = ExportListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportListRequest) ProtoMessage() {}
+
+func (x *ExportListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportListRequest.ProtoReflect.Descriptor instead.
+func (*ExportListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ExportListRequest) GetList() ListType {
+	if x != nil {
+		return x.List
+	}
+	return ListType_LIST_TYPE_UNSPECIFIED
+}
+
+func (x *ExportListRequest) GetFormat() ListFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ListFormat_LIST_FORMAT_TEXT
+}
+
+type ExportListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ExportListResponse) Reset() {
+	*x = ExportListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportListResponse) ProtoMessage() {}
+
+func (x *ExportListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportListResponse.ProtoReflect.Descriptor instead.
+func (*ExportListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ExportListResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Request and Response for CheckListConsistency method
+type CheckListConsistencyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CheckListConsistencyRequest) Reset() {
+	*x = CheckListConsistencyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckListConsistencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckListConsistencyRequest) ProtoMessage() {}
+
+func (x *CheckListConsistencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckListConsistencyRequest.ProtoReflect.Descriptor instead.
+func (*CheckListConsistencyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{22}
+}
+
+type CheckListConsistencyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Every pair of listed networks where one holds the other
+	Overlaps []*Overlap `protobuf:"bytes,1,rep,name=overlaps,proto3" json:"overlaps,omitempty"`
+}
+
+func (x *CheckListConsistencyResponse) Reset() {
+	*x = CheckListConsistencyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_login_info_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckListConsistencyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckListConsistencyResponse) ProtoMessage() {}
 
-func (x *AddToBlacklistResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[9]
+func (x *CheckListConsistencyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -515,44 +1796,46 @@ func (x *AddToBlacklistResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddToBlacklistResponse.ProtoReflect.Descriptor instead.
-func (*AddToBlacklistResponse) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use CheckListConsistencyResponse.ProtoReflect.Descriptor instead.
+func (*CheckListConsistencyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *AddToBlacklistResponse) GetMessage() string {
+func (x *CheckListConsistencyResponse) GetOverlaps() []*Overlap {
 	if x != nil {
-		return x.Message
+		return x.Overlaps
 	}
-	return ""
+	return nil
 }
 
-// Request and Response for RemoveFromBlacklist method
-type RemoveFromBlacklistRequest struct {
+type Overlap struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	SupernetList ListType `protobuf:"varint,1,opt,name=supernet_list,json=supernetList,proto3,enum=api.ListType" json:"supernet_list,omitempty"`
+	Supernet     string   `protobuf:"bytes,2,opt,name=supernet,proto3" json:"supernet,omitempty"`
+	SubnetList   ListType `protobuf:"varint,3,opt,name=subnet_list,json=subnetList,proto3,enum=api.ListType" json:"subnet_list,omitempty"`
+	Subnet       string   `protobuf:"bytes,4,opt,name=subnet,proto3" json:"subnet,omitempty"`
 }
 
-func (x *RemoveFromBlacklistRequest) Reset() {
-	*x = RemoveFromBlacklistRequest{}
+func (x *Overlap) Reset() {
+	*x = Overlap{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[10]
+		mi := &file_proto_login_info_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RemoveFromBlacklistRequest) String() string {
+func (x *Overlap) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemoveFromBlacklistRequest) ProtoMessage() {}
+func (*Overlap) ProtoMessage() {}
 
-func (x *RemoveFromBlacklistRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[10]
+func (x *Overlap) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -563,43 +1846,76 @@ func (x *RemoveFromBlacklistRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveFromBlacklistRequest.ProtoReflect.Descriptor instead.
-func (*RemoveFromBlacklistRequest) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use Overlap.ProtoReflect.Descriptor instead.
+func (*Overlap) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *RemoveFromBlacklistRequest) GetIp() string {
+func (x *Overlap) GetSupernetList() ListType {
 	if x != nil {
-		return x.Ip
+		return x.SupernetList
+	}
+	return ListType_LIST_TYPE_UNSPECIFIED
+}
+
+func (x *Overlap) GetSupernet() string {
+	if x != nil {
+		return x.Supernet
 	}
 	return ""
 }
 
-type RemoveFromBlacklistResponse struct {
+func (x *Overlap) GetSubnetList() ListType {
+	if x != nil {
+		return x.SubnetList
+	}
+	return ListType_LIST_TYPE_UNSPECIFIED
+}
+
+func (x *Overlap) GetSubnet() string {
+	if x != nil {
+		return x.Subnet
+	}
+	return ""
+}
+
+type ListEntry struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-}
-
-func (x *RemoveFromBlacklistResponse) Reset() {
-	*x = RemoveFromBlacklistResponse{}
+	List      ListType               `protobuf:"varint,1,opt,name=list,proto3,enum=api.ListType" json:"list,omitempty"`
+	Network   string                 `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	Reason    string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedBy string                 `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Ticket    string                 `protobuf:"bytes,5,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Labels    map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the network stops being listed, unset if it never does
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// The feed the network was pulled from, unset if it was added by hand
+	Source   string     `protobuf:"bytes,9,opt,name=source,proto3" json:"source,omitempty"`
+	Action   RuleAction `protobuf:"varint,10,opt,name=action,proto3,enum=api.RuleAction" json:"action,omitempty"`
+	Priority int32      `protobuf:"varint,11,opt,name=priority,proto3" json:"priority,omitempty"`
+}
+
+func (x *ListEntry) Reset() {
+	*x = ListEntry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_login_info_proto_msgTypes[11]
+		mi := &file_proto_login_info_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RemoveFromBlacklistResponse) String() string {
+func (x *ListEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemoveFromBlacklistResponse) ProtoMessage() {}
+func (*ListEntry) ProtoMessage() {}
 
-func (x *RemoveFromBlacklistResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_login_info_proto_msgTypes[11]
+func (x *ListEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_login_info_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -610,95 +1926,391 @@ func (x *RemoveFromBlacklistResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveFromBlacklistResponse.ProtoReflect.Descriptor instead.
-func (*RemoveFromBlacklistResponse) Descriptor() ([]byte, []int) {
-	return file_proto_login_info_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ListEntry.ProtoReflect.Descriptor instead.
+func (*ListEntry) Descriptor() ([]byte, []int) {
+	return file_proto_login_info_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *RemoveFromBlacklistResponse) GetMessage() string {
+func (x *ListEntry) GetList() ListType {
 	if x != nil {
-		return x.Message
+		return x.List
+	}
+	return ListType_LIST_TYPE_UNSPECIFIED
+}
+
+func (x *ListEntry) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *ListEntry) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ListEntry) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *ListEntry) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+func (x *ListEntry) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ListEntry) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ListEntry) GetSource() string {
+	if x != nil {
+		return x.Source
 	}
 	return ""
 }
 
+func (x *ListEntry) GetAction() RuleAction {
+	if x != nil {
+		return x.Action
+	}
+	return RuleAction_RULE_ACTION_UNSPECIFIED
+}
+
+func (x *ListEntry) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
 var File_proto_login_info_proto protoreflect.FileDescriptor
 
 var file_proto_login_info_proto_rawDesc = []byte{
 	0x0a, 0x16, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x5f, 0x69, 0x6e,
-	0x66, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x61, 0x70, 0x69, 0x22, 0x54, 0x0a,
-	0x10, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77,
-	0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77,
-	0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x02, 0x69, 0x70, 0x22, 0x4d, 0x0a, 0x11, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x75, 0x74, 0x68,
-	0x6f, 0x72, 0x69, 0x7a, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x61, 0x75,
-	0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x22, 0x3a, 0x0a, 0x12, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42, 0x75, 0x63, 0x6b, 0x65,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69,
-	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22, 0x2f,
-	0x0a, 0x13, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22,
-	0x27, 0x0a, 0x15, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22, 0x32, 0x0a, 0x16, 0x41, 0x64, 0x64, 0x54,
-	0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x2c, 0x0a, 0x1a,
-	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c,
-	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22, 0x37, 0x0a, 0x1b, 0x52, 0x65,
-	0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x22, 0x27, 0x0a, 0x15, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63,
-	0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
-	0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22, 0x32, 0x0a, 0x16,
-	0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x22, 0x2c, 0x0a, 0x1a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c,
-	0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22, 0x37,
-	0x0a, 0x1b, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x61, 0x63,
-	0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x66, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x61, 0x70, 0x69, 0x1a, 0x1e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x54,
+	0x0a, 0x10, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73,
+	0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73,
+	0x77, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x70, 0x22, 0xe2, 0x01, 0x0a, 0x11, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x75,
+	0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a,
+	0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x24, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x31, 0x0a, 0x09, 0x72, 0x65,
+	0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x52, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x3a, 0x0a,
+	0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x72,
+	0x65, 0x74, 0x72, 0x79, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0x7f, 0x0a, 0x0e, 0x52, 0x65, 0x6d,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x19, 0x0a, 0x05, 0x6c,
+	0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x6f,
+	0x67, 0x69, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73,
+	0x77, 0x6f, 0x72, 0x64, 0x88, 0x01, 0x01, 0x12, 0x13, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x02, 0x69, 0x70, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a, 0x06,
+	0x5f, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x69, 0x70, 0x22, 0x3a, 0x0a, 0x12, 0x52, 0x65,
+	0x73, 0x65, 0x74, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22, 0x2f, 0x0a, 0x13, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xf9, 0x02, 0x0a, 0x15, 0x41, 0x64, 0x64, 0x54,
+	0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x70, 0x12, 0x2b, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x62, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x42, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x3e, 0x0a,
+	0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f,
+	0x72, 0x63, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x5c, 0x0a, 0x16, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74,
+	0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
 	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0xd5, 0x03, 0x0a, 0x0b, 0x52, 0x61, 0x74, 0x65,
-	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x12, 0x3a, 0x0a, 0x09, 0x41, 0x75, 0x74, 0x68, 0x6f,
-	0x72, 0x69, 0x7a, 0x65, 0x12, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x6f,
-	0x72, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x61, 0x70,
-	0x69, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42, 0x75, 0x63, 0x6b,
-	0x65, 0x74, 0x12, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42, 0x75,
-	0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x70,
-	0x69, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68,
-	0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x64,
-	0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57,
-	0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x58, 0x0a, 0x13, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68,
-	0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65,
-	0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52,
-	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69,
-	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0e, 0x41, 0x64,
-	0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1a, 0x2e, 0x61,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x6c,
+	0x61, 0x70, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x4f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70, 0x52, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70,
+	0x73, 0x22, 0x2c, 0x0a, 0x1a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57,
+	0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x22,
+	0x37, 0x0a, 0x1b, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69,
+	0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xf9, 0x02, 0x0a, 0x15, 0x41, 0x64, 0x64,
+	0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x70, 0x12, 0x2b, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x3e,
+	0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66,
+	0x6f, 0x72, 0x63, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x5c, 0x0a, 0x16, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61,
+	0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72,
+	0x6c, 0x61, 0x70, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x4f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70, 0x52, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x6c, 0x61,
+	0x70, 0x73, 0x22, 0x2c, 0x0a, 0x1a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d,
+	0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70,
+	0x22, 0x37, 0x0a, 0x1b, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c,
+	0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x27, 0x0a, 0x15, 0x49, 0x6e, 0x73,
+	0x70, 0x65, 0x63, 0x74, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x70, 0x22, 0x42, 0x0a, 0x16, 0x49, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x4e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x07,
+	0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x8b, 0x01, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x24,
+	0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x05, 0x6f,
+	0x72, 0x64, 0x65, 0x72, 0x22, 0x60, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x26,
+	0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67,
+	0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x98, 0x01, 0x0a, 0x11, 0x49, 0x6d, 0x70, 0x6f, 0x72,
+	0x74, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x04,
+	0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x12,
+	0x27, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x72, 0x0a, 0x12, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6d, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x69, 0x6d, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x26, 0x0a,
+	0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x6e, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x06, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x39, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x65, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x22, 0x5f, 0x0a, 0x11, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61,
+	0x74, 0x22, 0x28, 0x0a, 0x12, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x1d, 0x0a, 0x1b, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x48, 0x0a, 0x1c, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x08, 0x6f, 0x76,
+	0x65, 0x72, 0x6c, 0x61, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70, 0x52, 0x08, 0x6f, 0x76, 0x65, 0x72,
+	0x6c, 0x61, 0x70, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x07, 0x4f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70,
+	0x12, 0x32, 0x0a, 0x0d, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x5f, 0x6c, 0x69, 0x73,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0c, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6e, 0x65, 0x74,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6e, 0x65, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6e, 0x65, 0x74,
+	0x12, 0x2e, 0x0a, 0x0b, 0x73, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x73, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x22, 0xd9, 0x03, 0x0a, 0x09, 0x4c, 0x69, 0x73,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x21, 0x0a, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77,
+	0x6f, 0x72, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x69, 0x63, 0x6b,
+	0x65, 0x74, 0x12, 0x32, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x2a, 0x57, 0x0a, 0x09, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1a, 0x0a, 0x16, 0x4c, 0x49, 0x4d, 0x49, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x09, 0x0a,
+	0x05, 0x4c, 0x4f, 0x47, 0x49, 0x4e, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x41, 0x53, 0x53,
+	0x57, 0x4f, 0x52, 0x44, 0x10, 0x02, 0x12, 0x06, 0x0a, 0x02, 0x49, 0x50, 0x10, 0x03, 0x12, 0x0d,
+	0x0a, 0x09, 0x42, 0x4c, 0x41, 0x43, 0x4b, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x04, 0x2a, 0x45, 0x0a,
+	0x09, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x4f,
+	0x52, 0x54, 0x5f, 0x4f, 0x52, 0x44, 0x45, 0x52, 0x5f, 0x4f, 0x4c, 0x44, 0x45, 0x53, 0x54, 0x5f,
+	0x46, 0x49, 0x52, 0x53, 0x54, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x4f, 0x52, 0x54, 0x5f,
+	0x4f, 0x52, 0x44, 0x45, 0x52, 0x5f, 0x4e, 0x45, 0x57, 0x45, 0x53, 0x54, 0x5f, 0x46, 0x49, 0x52,
+	0x53, 0x54, 0x10, 0x01, 0x2a, 0x4d, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x12, 0x14, 0x0a, 0x10, 0x4c, 0x49, 0x53, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41,
+	0x54, 0x5f, 0x54, 0x45, 0x58, 0x54, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4c, 0x49, 0x53, 0x54,
+	0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x43, 0x53, 0x56, 0x10, 0x01, 0x12, 0x14, 0x0a,
+	0x10, 0x4c, 0x49, 0x53, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x4a, 0x53, 0x4f,
+	0x4e, 0x10, 0x02, 0x2a, 0x3c, 0x0a, 0x0a, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x4d, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45,
+	0x5f, 0x4d, 0x45, 0x52, 0x47, 0x45, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x4d, 0x50, 0x4f,
+	0x52, 0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x52, 0x45, 0x50, 0x4c, 0x41, 0x43, 0x45, 0x10,
+	0x01, 0x2a, 0x8e, 0x01, 0x0a, 0x0a, 0x52, 0x75, 0x6c, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x1b, 0x0a, 0x17, 0x52, 0x55, 0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a,
+	0x11, 0x52, 0x55, 0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x4c, 0x4c,
+	0x4f, 0x57, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x52, 0x55, 0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x4e, 0x59, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x52, 0x55,
+	0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4c, 0x49, 0x4d, 0x49, 0x54, 0x10,
+	0x03, 0x12, 0x1f, 0x0a, 0x1b, 0x52, 0x55, 0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e,
+	0x5f, 0x42, 0x59, 0x50, 0x41, 0x53, 0x53, 0x5f, 0x49, 0x50, 0x5f, 0x4c, 0x49, 0x4d, 0x49, 0x54,
+	0x10, 0x04, 0x2a, 0x57, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x19,
+	0x0a, 0x15, 0x4c, 0x49, 0x53, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x4c, 0x49, 0x53,
+	0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x57, 0x48, 0x49, 0x54, 0x45, 0x4c, 0x49, 0x53, 0x54,
+	0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x4c, 0x49, 0x53, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x42, 0x4c, 0x41, 0x43, 0x4b, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x02, 0x32, 0xeb, 0x06, 0x0a, 0x0b,
+	0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x12, 0x3a, 0x0a, 0x09, 0x41,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x12, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x65, 0x74,
+	0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73,
+	0x65, 0x74, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x42, 0x75, 0x63, 0x6b, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0e, 0x41, 0x64, 0x64,
+	0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1a, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x64,
+	0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x13, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72,
+	0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1f, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74,
+	0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69,
+	0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49,
+	0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74,
+	0x12, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63,
+	0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61,
 	0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41,
-	0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x13, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46,
-	0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1f, 0x2e, 0x61,
-	0x70, 0x69, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x61,
-	0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e,
-	0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c,
-	0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x06, 0x5a, 0x04, 0x2e, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x13, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74,
+	0x12, 0x1f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f,
+	0x6d, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x20, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72,
+	0x6f, 0x6d, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0e, 0x49, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x4e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x70,
+	0x65, 0x63, 0x74, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x4e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34,
+	0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12,
+	0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x61, 0x63,
+	0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x49, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x3f, 0x0a, 0x0a, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x5b, 0x0a, 0x14,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x12, 0x20, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x2f, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -713,39 +2325,105 @@ func file_proto_login_info_proto_rawDescGZIP() []byte {
 	return file_proto_login_info_proto_rawDescData
 }
 
-var file_proto_login_info_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_proto_login_info_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_proto_login_info_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_proto_login_info_proto_goTypes = []any{
-	(*AuthorizeRequest)(nil),            // 0: api.AuthorizeRequest
-	(*AuthorizeResponse)(nil),           // 1: api.AuthorizeResponse
-	(*ResetBucketRequest)(nil),          // 2: api.ResetBucketRequest
-	(*ResetBucketResponse)(nil),         // 3: api.ResetBucketResponse
-	(*AddToWhitelistRequest)(nil),       // 4: api.AddToWhitelistRequest
-	(*AddToWhitelistResponse)(nil),      // 5: api.AddToWhitelistResponse
-	(*RemoveFromWhitelistRequest)(nil),  // 6: api.RemoveFromWhitelistRequest
-	(*RemoveFromWhitelistResponse)(nil), // 7: api.RemoveFromWhitelistResponse
-	(*AddToBlacklistRequest)(nil),       // 8: api.AddToBlacklistRequest
-	(*AddToBlacklistResponse)(nil),      // 9: api.AddToBlacklistResponse
-	(*RemoveFromBlacklistRequest)(nil),  // 10: api.RemoveFromBlacklistRequest
-	(*RemoveFromBlacklistResponse)(nil), // 11: api.RemoveFromBlacklistResponse
+	(LimitType)(0),                       // 0: api.LimitType
+	(SortOrder)(0),                       // 1: api.SortOrder
+	(ListFormat)(0),                      // 2: api.ListFormat
+	(ImportMode)(0),                      // 3: api.ImportMode
+	(RuleAction)(0),                      // 4: api.RuleAction
+	(ListType)(0),                        // 5: api.ListType
+	(*AuthorizeRequest)(nil),             // 6: api.AuthorizeRequest
+	(*AuthorizeResponse)(nil),            // 7: api.AuthorizeResponse
+	(*RemainingQuota)(nil),               // 8: api.RemainingQuota
+	(*ResetBucketRequest)(nil),           // 9: api.ResetBucketRequest
+	(*ResetBucketResponse)(nil),          // 10: api.ResetBucketResponse
+	(*AddToWhitelistRequest)(nil),        // 11: api.AddToWhitelistRequest
+	(*AddToWhitelistResponse)(nil),       // 12: api.AddToWhitelistResponse
+	(*RemoveFromWhitelistRequest)(nil),   // 13: api.RemoveFromWhitelistRequest
+	(*RemoveFromWhitelistResponse)(nil),  // 14: api.RemoveFromWhitelistResponse
+	(*AddToBlacklistRequest)(nil),        // 15: api.AddToBlacklistRequest
+	(*AddToBlacklistResponse)(nil),       // 16: api.AddToBlacklistResponse
+	(*RemoveFromBlacklistRequest)(nil),   // 17: api.RemoveFromBlacklistRequest
+	(*RemoveFromBlacklistResponse)(nil),  // 18: api.RemoveFromBlacklistResponse
+	(*InspectNetworkRequest)(nil),        // 19: api.InspectNetworkRequest
+	(*InspectNetworkResponse)(nil),       // 20: api.InspectNetworkResponse
+	(*ListRequest)(nil),                  // 21: api.ListRequest
+	(*ListResponse)(nil),                 // 22: api.ListResponse
+	(*ImportListRequest)(nil),            // 23: api.ImportListRequest
+	(*ImportListResponse)(nil),           // 24: api.ImportListResponse
+	(*LineError)(nil),                    // 25: api.LineError
+	(*ExportListRequest)(nil),            // 26: api.ExportListRequest
+	(*ExportListResponse)(nil),           // 27: api.ExportListResponse
+	(*CheckListConsistencyRequest)(nil),  // 28: api.CheckListConsistencyRequest
+	(*CheckListConsistencyResponse)(nil), // 29: api.CheckListConsistencyResponse
+	(*Overlap)(nil),                      // 30: api.Overlap
+	(*ListEntry)(nil),                    // 31: api.ListEntry
+	nil,                                  // 32: api.AddToWhitelistRequest.LabelsEntry
+	nil,                                  // 33: api.AddToBlacklistRequest.LabelsEntry
+	nil,                                  // 34: api.ListEntry.LabelsEntry
+	(*durationpb.Duration)(nil),          // 35: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),        // 36: google.protobuf.Timestamp
 }
 var file_proto_login_info_proto_depIdxs = []int32{
-	0,  // 0: api.RateLimiter.Authorize:input_type -> api.AuthorizeRequest
-	2,  // 1: api.RateLimiter.ResetBucket:input_type -> api.ResetBucketRequest
-	4,  // 2: api.RateLimiter.AddToWhitelist:input_type -> api.AddToWhitelistRequest
-	6,  // 3: api.RateLimiter.RemoveFromWhitelist:input_type -> api.RemoveFromWhitelistRequest
-	8,  // 4: api.RateLimiter.AddToBlacklist:input_type -> api.AddToBlacklistRequest
-	10, // 5: api.RateLimiter.RemoveFromBlacklist:input_type -> api.RemoveFromBlacklistRequest
-	1,  // 6: api.RateLimiter.Authorize:output_type -> api.AuthorizeResponse
-	3,  // 7: api.RateLimiter.ResetBucket:output_type -> api.ResetBucketResponse
-	5,  // 8: api.RateLimiter.AddToWhitelist:output_type -> api.AddToWhitelistResponse
-	7,  // 9: api.RateLimiter.RemoveFromWhitelist:output_type -> api.RemoveFromWhitelistResponse
-	9,  // 10: api.RateLimiter.AddToBlacklist:output_type -> api.AddToBlacklistResponse
-	11, // 11: api.RateLimiter.RemoveFromBlacklist:output_type -> api.RemoveFromBlacklistResponse
-	6,  // [6:12] is the sub-list for method output_type
-	0,  // [0:6] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	0,  // 0: api.AuthorizeResponse.limit:type_name -> api.LimitType
+	8,  // 1: api.AuthorizeResponse.remaining:type_name -> api.RemainingQuota
+	35, // 2: api.AuthorizeResponse.retry_after:type_name -> google.protobuf.Duration
+	35, // 3: api.AddToWhitelistRequest.ttl:type_name -> google.protobuf.Duration
+	32, // 4: api.AddToWhitelistRequest.labels:type_name -> api.AddToWhitelistRequest.LabelsEntry
+	4,  // 5: api.AddToWhitelistRequest.action:type_name -> api.RuleAction
+	30, // 6: api.AddToWhitelistResponse.overlaps:type_name -> api.Overlap
+	35, // 7: api.AddToBlacklistRequest.ttl:type_name -> google.protobuf.Duration
+	33, // 8: api.AddToBlacklistRequest.labels:type_name -> api.AddToBlacklistRequest.LabelsEntry
+	4,  // 9: api.AddToBlacklistRequest.action:type_name -> api.RuleAction
+	30, // 10: api.AddToBlacklistResponse.overlaps:type_name -> api.Overlap
+	31, // 11: api.InspectNetworkResponse.entries:type_name -> api.ListEntry
+	1,  // 12: api.ListRequest.order:type_name -> api.SortOrder
+	31, // 13: api.ListResponse.entries:type_name -> api.ListEntry
+	5,  // 14: api.ImportListRequest.list:type_name -> api.ListType
+	2,  // 15: api.ImportListRequest.format:type_name -> api.ListFormat
+	3,  // 16: api.ImportListRequest.mode:type_name -> api.ImportMode
+	25, // 17: api.ImportListResponse.errors:type_name -> api.LineError
+	5,  // 18: api.ExportListRequest.list:type_name -> api.ListType
+	2,  // 19: api.ExportListRequest.format:type_name -> api.ListFormat
+	30, // 20: api.CheckListConsistencyResponse.overlaps:type_name -> api.Overlap
+	5,  // 21: api.Overlap.supernet_list:type_name -> api.ListType
+	5,  // 22: api.Overlap.subnet_list:type_name -> api.ListType
+	5,  // 23: api.ListEntry.list:type_name -> api.ListType
+	34, // 24: api.ListEntry.labels:type_name -> api.ListEntry.LabelsEntry
+	36, // 25: api.ListEntry.created_at:type_name -> google.protobuf.Timestamp
+	36, // 26: api.ListEntry.expires_at:type_name -> google.protobuf.Timestamp
+	4,  // 27: api.ListEntry.action:type_name -> api.RuleAction
+	6,  // 28: api.RateLimiter.Authorize:input_type -> api.AuthorizeRequest
+	9,  // 29: api.RateLimiter.ResetBucket:input_type -> api.ResetBucketRequest
+	11, // 30: api.RateLimiter.AddToWhitelist:input_type -> api.AddToWhitelistRequest
+	13, // 31: api.RateLimiter.RemoveFromWhitelist:input_type -> api.RemoveFromWhitelistRequest
+	15, // 32: api.RateLimiter.AddToBlacklist:input_type -> api.AddToBlacklistRequest
+	17, // 33: api.RateLimiter.RemoveFromBlacklist:input_type -> api.RemoveFromBlacklistRequest
+	19, // 34: api.RateLimiter.InspectNetwork:input_type -> api.InspectNetworkRequest
+	21, // 35: api.RateLimiter.ListWhitelist:input_type -> api.ListRequest
+	21, // 36: api.RateLimiter.ListBlacklist:input_type -> api.ListRequest
+	23, // 37: api.RateLimiter.ImportList:input_type -> api.ImportListRequest
+	26, // 38: api.RateLimiter.ExportList:input_type -> api.ExportListRequest
+	28, // 39: api.RateLimiter.CheckListConsistency:input_type -> api.CheckListConsistencyRequest
+	7,  // 40: api.RateLimiter.Authorize:output_type -> api.AuthorizeResponse
+	10, // 41: api.RateLimiter.ResetBucket:output_type -> api.ResetBucketResponse
+	12, // 42: api.RateLimiter.AddToWhitelist:output_type -> api.AddToWhitelistResponse
+	14, // 43: api.RateLimiter.RemoveFromWhitelist:output_type -> api.RemoveFromWhitelistResponse
+	16, // 44: api.RateLimiter.AddToBlacklist:output_type -> api.AddToBlacklistResponse
+	18, // 45: api.RateLimiter.RemoveFromBlacklist:output_type -> api.RemoveFromBlacklistResponse
+	20, // 46: api.RateLimiter.InspectNetwork:output_type -> api.InspectNetworkResponse
+	22, // 47: api.RateLimiter.ListWhitelist:output_type -> api.ListResponse
+	22, // 48: api.RateLimiter.ListBlacklist:output_type -> api.ListResponse
+	24, // 49: api.RateLimiter.ImportList:output_type -> api.ImportListResponse
+	27, // 50: api.RateLimiter.ExportList:output_type -> api.ExportListResponse
+	29, // 51: api.RateLimiter.CheckListConsistency:output_type -> api.CheckListConsistencyResponse
+	40, // [40:52] is the sub-list for method output_type
+	28, // [28:40] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_proto_login_info_proto_init() }
@@ -779,7 +2457,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*ResetBucketRequest); i {
+			switch v := v.(*RemainingQuota); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -791,7 +2469,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[3].Exporter = func(v any, i int) any {
-			switch v := v.(*ResetBucketResponse); i {
+			switch v := v.(*ResetBucketRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -803,7 +2481,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[4].Exporter = func(v any, i int) any {
-			switch v := v.(*AddToWhitelistRequest); i {
+			switch v := v.(*ResetBucketResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -815,7 +2493,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[5].Exporter = func(v any, i int) any {
-			switch v := v.(*AddToWhitelistResponse); i {
+			switch v := v.(*AddToWhitelistRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -827,7 +2505,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[6].Exporter = func(v any, i int) any {
-			switch v := v.(*RemoveFromWhitelistRequest); i {
+			switch v := v.(*AddToWhitelistResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -839,7 +2517,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[7].Exporter = func(v any, i int) any {
-			switch v := v.(*RemoveFromWhitelistResponse); i {
+			switch v := v.(*RemoveFromWhitelistRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -851,7 +2529,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[8].Exporter = func(v any, i int) any {
-			switch v := v.(*AddToBlacklistRequest); i {
+			switch v := v.(*RemoveFromWhitelistResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -863,7 +2541,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[9].Exporter = func(v any, i int) any {
-			switch v := v.(*AddToBlacklistResponse); i {
+			switch v := v.(*AddToBlacklistRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -875,7 +2553,7 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[10].Exporter = func(v any, i int) any {
-			switch v := v.(*RemoveFromBlacklistRequest); i {
+			switch v := v.(*AddToBlacklistResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -887,6 +2565,18 @@ func file_proto_login_info_proto_init() {
 			}
 		}
 		file_proto_login_info_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveFromBlacklistRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[12].Exporter = func(v any, i int) any {
 			switch v := v.(*RemoveFromBlacklistResponse); i {
 			case 0:
 				return &v.state
@@ -898,19 +2588,177 @@ func file_proto_login_info_proto_init() {
 				return nil
 			}
 		}
+		file_proto_login_info_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*InspectNetworkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*InspectNetworkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*ListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*ListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*LineError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*ExportListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*ExportListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckListConsistencyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckListConsistencyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*Overlap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_login_info_proto_msgTypes[25].Exporter = func(v any, i int) any {
+			switch v := v.(*ListEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
+	file_proto_login_info_proto_msgTypes[2].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_login_info_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   12,
+			NumEnums:      6,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_login_info_proto_goTypes,
 		DependencyIndexes: file_proto_login_info_proto_depIdxs,
+		EnumInfos:         file_proto_login_info_proto_enumTypes,
 		MessageInfos:      file_proto_login_info_proto_msgTypes,
 	}.Build()
 	File_proto_login_info_proto = out.File
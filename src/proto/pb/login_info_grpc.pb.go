@@ -19,12 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RateLimiter_Authorize_FullMethodName           = "/api.RateLimiter/Authorize"
-	RateLimiter_ResetBucket_FullMethodName         = "/api.RateLimiter/ResetBucket"
-	RateLimiter_AddToWhitelist_FullMethodName      = "/api.RateLimiter/AddToWhitelist"
-	RateLimiter_RemoveFromWhitelist_FullMethodName = "/api.RateLimiter/RemoveFromWhitelist"
-	RateLimiter_AddToBlacklist_FullMethodName      = "/api.RateLimiter/AddToBlacklist"
-	RateLimiter_RemoveFromBlacklist_FullMethodName = "/api.RateLimiter/RemoveFromBlacklist"
+	RateLimiter_Authorize_FullMethodName            = "/api.RateLimiter/Authorize"
+	RateLimiter_ResetBucket_FullMethodName          = "/api.RateLimiter/ResetBucket"
+	RateLimiter_AddToWhitelist_FullMethodName       = "/api.RateLimiter/AddToWhitelist"
+	RateLimiter_RemoveFromWhitelist_FullMethodName  = "/api.RateLimiter/RemoveFromWhitelist"
+	RateLimiter_AddToBlacklist_FullMethodName       = "/api.RateLimiter/AddToBlacklist"
+	RateLimiter_RemoveFromBlacklist_FullMethodName  = "/api.RateLimiter/RemoveFromBlacklist"
+	RateLimiter_InspectNetwork_FullMethodName       = "/api.RateLimiter/InspectNetwork"
+	RateLimiter_ListWhitelist_FullMethodName        = "/api.RateLimiter/ListWhitelist"
+	RateLimiter_ListBlacklist_FullMethodName        = "/api.RateLimiter/ListBlacklist"
+	RateLimiter_ImportList_FullMethodName           = "/api.RateLimiter/ImportList"
+	RateLimiter_ExportList_FullMethodName           = "/api.RateLimiter/ExportList"
+	RateLimiter_CheckListConsistency_FullMethodName = "/api.RateLimiter/CheckListConsistency"
 )
 
 // RateLimiterClient is the client API for RateLimiter service.
@@ -39,6 +45,12 @@ type RateLimiterClient interface {
 	RemoveFromWhitelist(ctx context.Context, in *RemoveFromWhitelistRequest, opts ...grpc.CallOption) (*RemoveFromWhitelistResponse, error)
 	AddToBlacklist(ctx context.Context, in *AddToBlacklistRequest, opts ...grpc.CallOption) (*AddToBlacklistResponse, error)
 	RemoveFromBlacklist(ctx context.Context, in *RemoveFromBlacklistRequest, opts ...grpc.CallOption) (*RemoveFromBlacklistResponse, error)
+	InspectNetwork(ctx context.Context, in *InspectNetworkRequest, opts ...grpc.CallOption) (*InspectNetworkResponse, error)
+	ListWhitelist(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	ListBlacklist(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	ImportList(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportListRequest, ImportListResponse], error)
+	ExportList(ctx context.Context, in *ExportListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportListResponse], error)
+	CheckListConsistency(ctx context.Context, in *CheckListConsistencyRequest, opts ...grpc.CallOption) (*CheckListConsistencyResponse, error)
 }
 
 type rateLimiterClient struct {
@@ -109,6 +121,78 @@ func (c *rateLimiterClient) RemoveFromBlacklist(ctx context.Context, in *RemoveF
 	return out, nil
 }
 
+func (c *rateLimiterClient) InspectNetwork(ctx context.Context, in *InspectNetworkRequest, opts ...grpc.CallOption) (*InspectNetworkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectNetworkResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_InspectNetwork_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) ListWhitelist(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_ListWhitelist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) ListBlacklist(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_ListBlacklist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) ImportList(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportListRequest, ImportListResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateLimiter_ServiceDesc.Streams[0], RateLimiter_ImportList_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportListRequest, ImportListResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimiter_ImportListClient = grpc.ClientStreamingClient[ImportListRequest, ImportListResponse]
+
+func (c *rateLimiterClient) ExportList(ctx context.Context, in *ExportListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportListResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateLimiter_ServiceDesc.Streams[1], RateLimiter_ExportList_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportListRequest, ExportListResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimiter_ExportListClient = grpc.ServerStreamingClient[ExportListResponse]
+
+func (c *rateLimiterClient) CheckListConsistency(ctx context.Context, in *CheckListConsistencyRequest, opts ...grpc.CallOption) (*CheckListConsistencyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckListConsistencyResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_CheckListConsistency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RateLimiterServer is the server API for RateLimiter service.
 // All implementations must embed UnimplementedRateLimiterServer
 // for forward compatibility.
@@ -121,6 +205,12 @@ type RateLimiterServer interface {
 	RemoveFromWhitelist(context.Context, *RemoveFromWhitelistRequest) (*RemoveFromWhitelistResponse, error)
 	AddToBlacklist(context.Context, *AddToBlacklistRequest) (*AddToBlacklistResponse, error)
 	RemoveFromBlacklist(context.Context, *RemoveFromBlacklistRequest) (*RemoveFromBlacklistResponse, error)
+	InspectNetwork(context.Context, *InspectNetworkRequest) (*InspectNetworkResponse, error)
+	ListWhitelist(context.Context, *ListRequest) (*ListResponse, error)
+	ListBlacklist(context.Context, *ListRequest) (*ListResponse, error)
+	ImportList(grpc.ClientStreamingServer[ImportListRequest, ImportListResponse]) error
+	ExportList(*ExportListRequest, grpc.ServerStreamingServer[ExportListResponse]) error
+	CheckListConsistency(context.Context, *CheckListConsistencyRequest) (*CheckListConsistencyResponse, error)
 	mustEmbedUnimplementedRateLimiterServer()
 }
 
@@ -149,6 +239,24 @@ func (UnimplementedRateLimiterServer) AddToBlacklist(context.Context, *AddToBlac
 func (UnimplementedRateLimiterServer) RemoveFromBlacklist(context.Context, *RemoveFromBlacklistRequest) (*RemoveFromBlacklistResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveFromBlacklist not implemented")
 }
+func (UnimplementedRateLimiterServer) InspectNetwork(context.Context, *InspectNetworkRequest) (*InspectNetworkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectNetwork not implemented")
+}
+func (UnimplementedRateLimiterServer) ListWhitelist(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWhitelist not implemented")
+}
+func (UnimplementedRateLimiterServer) ListBlacklist(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlacklist not implemented")
+}
+func (UnimplementedRateLimiterServer) ImportList(grpc.ClientStreamingServer[ImportListRequest, ImportListResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportList not implemented")
+}
+func (UnimplementedRateLimiterServer) ExportList(*ExportListRequest, grpc.ServerStreamingServer[ExportListResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportList not implemented")
+}
+func (UnimplementedRateLimiterServer) CheckListConsistency(context.Context, *CheckListConsistencyRequest) (*CheckListConsistencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckListConsistency not implemented")
+}
 func (UnimplementedRateLimiterServer) mustEmbedUnimplementedRateLimiterServer() {}
 func (UnimplementedRateLimiterServer) testEmbeddedByValue()                     {}
 
@@ -278,6 +386,96 @@ func _RateLimiter_RemoveFromBlacklist_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RateLimiter_InspectNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).InspectNetwork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_InspectNetwork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).InspectNetwork(ctx, req.(*InspectNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_ListWhitelist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).ListWhitelist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_ListWhitelist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).ListWhitelist(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_ListBlacklist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).ListBlacklist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_ListBlacklist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).ListBlacklist(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_ImportList_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RateLimiterServer).ImportList(&grpc.GenericServerStream[ImportListRequest, ImportListResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimiter_ImportListServer = grpc.ClientStreamingServer[ImportListRequest, ImportListResponse]
+
+func _RateLimiter_ExportList_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RateLimiterServer).ExportList(m, &grpc.GenericServerStream[ExportListRequest, ExportListResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimiter_ExportListServer = grpc.ServerStreamingServer[ExportListResponse]
+
+func _RateLimiter_CheckListConsistency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckListConsistencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).CheckListConsistency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_CheckListConsistency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).CheckListConsistency(ctx, req.(*CheckListConsistencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RateLimiter_ServiceDesc is the grpc.ServiceDesc for RateLimiter service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -309,7 +507,34 @@ var RateLimiter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveFromBlacklist",
 			Handler:    _RateLimiter_RemoveFromBlacklist_Handler,
 		},
+		{
+			MethodName: "InspectNetwork",
+			Handler:    _RateLimiter_InspectNetwork_Handler,
+		},
+		{
+			MethodName: "ListWhitelist",
+			Handler:    _RateLimiter_ListWhitelist_Handler,
+		},
+		{
+			MethodName: "ListBlacklist",
+			Handler:    _RateLimiter_ListBlacklist_Handler,
+		},
+		{
+			MethodName: "CheckListConsistency",
+			Handler:    _RateLimiter_CheckListConsistency_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportList",
+			Handler:       _RateLimiter_ImportList_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportList",
+			Handler:       _RateLimiter_ExportList_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/login_info.proto",
 }
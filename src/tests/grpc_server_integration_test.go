@@ -2,6 +2,7 @@ package api_test
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/TheJubadze/RateLimiter/proto/pb"
 	"github.com/onsi/ginkgo/v2"
@@ -72,6 +73,7 @@ var _ = ginkgo.Describe("GrpcServer Integration Tests", func() {
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			gomega.Expect(resp.Authorized).To(gomega.BeFalse())
 			gomega.Expect(resp.Message).To(gomega.Equal("Unauthorized: IP is blacklisted"))
+			gomega.Expect(resp.Limit).To(gomega.Equal(pb.LimitType_BLACKLIST))
 			rr := &pb.RemoveFromBlacklistRequest{Ip: "192.168.1.1/24"}
 			_, _ = client.RemoveFromBlacklist(context.Background(), rr)
 		})
@@ -88,6 +90,8 @@ var _ = ginkgo.Describe("GrpcServer Integration Tests", func() {
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			gomega.Expect(resp.Authorized).To(gomega.BeFalse())
 			gomega.Expect(resp.Message).To(gomega.Equal("Login rate limit exceeded"))
+			gomega.Expect(resp.Limit).To(gomega.Equal(pb.LimitType_LOGIN))
+			gomega.Expect(resp.RetryAfter.AsDuration()).To(gomega.BeNumerically(">", 0))
 		})
 
 		ginkgo.It("should not authorize when password rate limit is exceeded", func() {
@@ -102,6 +106,8 @@ var _ = ginkgo.Describe("GrpcServer Integration Tests", func() {
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			gomega.Expect(resp.Authorized).To(gomega.BeFalse())
 			gomega.Expect(resp.Message).To(gomega.Equal("Password rate limit exceeded"))
+			gomega.Expect(resp.Limit).To(gomega.Equal(pb.LimitType_PASSWORD))
+			gomega.Expect(resp.RetryAfter.AsDuration()).To(gomega.BeNumerically(">", 0))
 		})
 
 		ginkgo.It("should not authorize when IP rate limit is exceeded", func() {
@@ -116,6 +122,101 @@ var _ = ginkgo.Describe("GrpcServer Integration Tests", func() {
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			gomega.Expect(resp.Authorized).To(gomega.BeFalse())
 			gomega.Expect(resp.Message).To(gomega.Equal("IP rate limit exceeded"))
+			gomega.Expect(resp.Limit).To(gomega.Equal(pb.LimitType_IP))
+			gomega.Expect(resp.RetryAfter.AsDuration()).To(gomega.BeNumerically(">", 0))
 		})
 	})
+
+	ginkgo.Context("ListWhitelist", func() {
+		ginkgo.It("should page through the networks", func() {
+			var added []string
+			for i := 0; i < 5; i++ {
+				network := fmt.Sprintf("10.50.%d.0/24", i)
+				_, err := client.AddToWhitelist(context.Background(), &pb.AddToWhitelistRequest{Ip: network})
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				added = append(added, network)
+			}
+			defer func() {
+				for _, network := range added {
+					_, _ = client.RemoveFromWhitelist(context.Background(), &pb.RemoveFromWhitelistRequest{Ip: network})
+				}
+			}()
+
+			var listed []string
+			pages := 0
+			req := &pb.ListRequest{PageSize: 2, Contains: "10.50.0.0/16"}
+			for {
+				resp, err := client.ListWhitelist(context.Background(), req)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				pages++
+				for _, entry := range resp.Entries {
+					listed = append(listed, entry.Network)
+				}
+				if resp.NextPageToken == "" {
+					break
+				}
+				req.PageToken = resp.NextPageToken
+			}
+
+			gomega.Expect(pages).To(gomega.Equal(3))
+			gomega.Expect(listed).To(gomega.ConsistOf(added))
+		})
+
+		ginkgo.It("should only list the networks containing the given one", func() {
+			resp, err := client.ListBlacklist(context.Background(), &pb.ListRequest{PageSize: 10, Contains: "10.99.1.1"})
+
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(resp.Entries).To(gomega.HaveLen(1))
+			// Listed with host bits set before the network column was cidr
+			gomega.Expect(resp.Entries[0].Network).To(gomega.Equal("10.99.0.0/16"))
+		})
+	})
+})
+
+var _ = ginkgo.Describe("GrpcServer Integration Tests Without List Cache", func() {
+	var (
+		client pb.RateLimiterClient
+		conn   *grpc.ClientConn
+	)
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		conn, err = grpc.NewClient("rate-limiter-uncached:8081", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		client = pb.NewRateLimiterClient(conn)
+	})
+
+	ginkgo.AfterEach(func() {
+		_ = conn.Close()
+	})
+
+	ginkgo.It("should not authorize IP in a blacklisted network", func() {
+		_, err := client.AddToBlacklist(context.Background(), &pb.AddToBlacklistRequest{Ip: "10.60.1.1/16"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		defer func() {
+			_, _ = client.RemoveFromBlacklist(context.Background(), &pb.RemoveFromBlacklistRequest{Ip: "10.60.0.0/16"})
+		}()
+
+		resp, err := client.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: "10.60.200.7"})
+
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(resp.Authorized).To(gomega.BeFalse())
+		gomega.Expect(resp.Limit).To(gomega.Equal(pb.LimitType_BLACKLIST))
+	})
+
+	ginkgo.It("should not authorize IP in a network masked by the migration", func() {
+		resp, err := client.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: "10.99.200.7"})
+
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(resp.Authorized).To(gomega.BeFalse())
+		gomega.Expect(resp.Limit).To(gomega.Equal(pb.LimitType_BLACKLIST))
+	})
+
+	ginkgo.It("should authorize IP outside the blacklisted networks", func() {
+		resp, err := client.Authorize(context.Background(), &pb.AuthorizeRequest{Ip: "10.61.0.1"})
+
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(resp.Authorized).To(gomega.BeTrue())
+	})
 })
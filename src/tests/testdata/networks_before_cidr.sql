@@ -0,0 +1,10 @@
+-- Lists a network with host bits set the way it was stored before the
+-- network column became cidr, so migration 00008 has to mask it. Does
+-- nothing once the column is cidr.
+DO $$
+BEGIN
+  IF (SELECT data_type FROM information_schema.columns
+      WHERE table_name = 'blacklist' AND column_name = 'network') <> 'cidr' THEN
+    EXECUTE 'INSERT INTO "blacklist" ("network") VALUES (''10.99.1.1/16'') ON CONFLICT DO NOTHING';
+  END IF;
+END $$;